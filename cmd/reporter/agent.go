@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"RPC-report/pkg/reporter"
+)
+
+// agentFlags параметры командной строки агента; имеют приоритет над файлом
+type agentFlags struct {
+	config        configFlags
+	interval      time.Duration
+	jitter        time.Duration
+	jitterSet     bool // -jitter задан явно: 0 отключает добавку
+	metricsListen string
+}
+
+// runAgent запускает режим агента: reporter agent [флаги]
+func runAgent(args []string) int {
+	fs := newFlagSet("agent", "[flags]")
+	var flags agentFlags
+	flags.config.register(fs)
+	fs.DurationVar(&flags.interval, "interval", 0, "Report interval (overrides config)")
+	fs.DurationVar(&flags.jitter, "jitter", 0, "Maximum random delay added to interval; 0 disables it (overrides config)")
+	fs.StringVar(&flags.metricsListen, "metrics-listen", "", "Serve Prometheus metrics on this address, e.g. :9273 (overrides config)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	fs.Visit(func(f *flag.Flag) { flags.jitterSet = flags.jitterSet || f.Name == "jitter" })
+
+	config, err := loadAgentConfig(flags)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return exitFailure
+	}
+
+	rep := reporter.New(config)
+
+	// SIGINT/SIGTERM завершают агент, SIGHUP перечитывает конфигурацию
+	ctx, stop := interruptContext()
+	defer stop()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				config, err := loadAgentConfig(flags)
+				if err != nil {
+					fmt.Printf("Error reloading config, keeping previous: %v\n", err)
+					continue
+				}
+				rep.SetConfig(config)
+				fmt.Println("Configuration reloaded")
+			}
+		}
+	}()
+
+	fmt.Printf("Starting agent for host %s (interval %s, jitter %s)\n",
+		reporter.GetHostID(), config.Interval, config.Jitter)
+	if err := rep.Run(ctx); err != nil {
+		fmt.Printf("Agent stopped with error: %v\n", err)
+		return exitFailure
+	}
+	fmt.Println("Agent stopped")
+	return exitOK
+}
+
+// loadAgentConfig строит конфигурацию через reporter.LoadConfig;
+// -interval, -jitter и -metrics-listen применяются поверх -set
+func loadAgentConfig(flags agentFlags) (*reporter.Config, error) {
+	overrides := append([]string(nil), flags.config.overrides...)
+	if flags.interval > 0 {
+		overrides = append(overrides, "interval="+flags.interval.String())
+	}
+	if flags.jitterSet {
+		overrides = append(overrides, "jitter="+flags.jitter.String())
+	}
+	if flags.metricsListen != "" {
+		overrides = append(overrides, "metrics_listen="+flags.metricsListen)
+	}
+	return reporter.LoadConfig(flags.config.file, overrides)
+}
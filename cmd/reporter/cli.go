@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"RPC-report/pkg/reporter"
+)
+
+// Коды завершения команд
+const (
+	exitOK      = 0 // Успех
+	exitFailure = 1 // Ошибка выполнения; validate - отчет неверен, diff - отчеты различаются
+	exitUsage   = 2 // Неверные аргументы; diff - ошибка чтения отчетов, как у diff(1)
+)
+
+// command подкоманда CLI
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+func commandList() []command {
+	return []command{
+		{"run", "Collect a report, save it and send it to the API (default)", runLegacy},
+		{"collect", "Collect a report and write it to a file", runCollect},
+		{"send", "Send a saved report file to the API", runSend},
+		{"agent", "Send reports periodically until stopped", runAgent},
+		{"validate", "Check saved report files", runValidate},
+		{"diff", "Show differences between two saved reports", runDiff},
+		{"config", "Show the effective configuration", runConfig},
+	}
+}
+
+// printUsage печатает список команд
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: reporter <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commandList() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'reporter <command> -h' for command flags.")
+}
+
+// newFlagSet создает набор флагов команды; usage - строка аргументов
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: reporter %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags разбирает флаги; при -h или ошибке возвращает код завершения
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// usageError сообщает о неверных аргументах команды
+func usageError(fs *flag.FlagSet, format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	fs.Usage()
+	return exitUsage
+}
+
+// configFlags флаги загрузки конфигурации, общие для команд
+type configFlags struct {
+	file      string
+	overrides stringList
+}
+
+func (f *configFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "config", "", "Config file (YAML, JSON or TOML)")
+	fs.Var(&f.overrides, "set", "Override a config key, key=value (repeatable)")
+}
+
+func (f *configFlags) load() (*reporter.Config, error) {
+	return reporter.LoadConfig(f.file, f.overrides)
+}
+
+// stringList флаг, который можно указать несколько раз
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// interruptContext отменяется по SIGINT/SIGTERM
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
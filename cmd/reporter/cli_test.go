@@ -0,0 +1,193 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"RPC-report/pkg/reporter"
+)
+
+// runCLI выполняет команду, перехватывая stdout и stderr, и возвращает
+// код завершения и вывод обоих потоков
+func runCLI(t *testing.T, run func([]string) int, args ...string) (int, string) {
+	t.Helper()
+	out, err := os.Create(filepath.Join(t.TempDir(), "output"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = out, out
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		reporter.LogOutput = stdout
+	}()
+	reporter.LogOutput = out
+
+	code := run(args)
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(data)
+}
+
+// cliEnv готовит каталог теста: файл конфигурации без очереди и повторов
+// (найденный через REPORTER_CONFIG вместо системного) и два отчета,
+// отличающиеся версией ядра и загрузкой CPU
+func cliEnv(t *testing.T) (dir string) {
+	t.Helper()
+	dir = t.TempDir()
+	config := "spool_dir: \"\"\n" +
+		"state_file: " + filepath.Join(dir, "state.json") + "\n" +
+		"timeout: 5s\n" +
+		"retry:\n  max_attempts: 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(reporter.EnvConfigFile, filepath.Join(dir, "config.yaml"))
+
+	writeReport(t, filepath.Join(dir, "old.json"), "6.1.0-18-amd64", 12.5)
+	writeReport(t, filepath.Join(dir, "old-busy.json"), "6.1.0-18-amd64", 99)
+	writeReport(t, filepath.Join(dir, "new.json"), "6.1.0-20-amd64", 12.5)
+	if err := os.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{"api_version":"1.0"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeReport(t *testing.T, path, kernel string, usage float64) {
+	t.Helper()
+	generated := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	report := &reporter.SystemReport{
+		APIVersion: reporter.APIVersion,
+		Generated:  generated,
+		Reports: []reporter.Report{{
+			HostID:       "web1",
+			ReportNumber: 1,
+			Timestamp:    generated,
+			Sections: map[string]reporter.Section{
+				"1": {Name: reporter.SectionHost, Title: "HOST INFORMATION", Data: &reporter.HostInfo{
+					Hostname: "web1", OS: "debian 12.5", Kernel: kernel,
+					Uptime: reporter.UptimeInfo{Hours: 240, BootTime: generated.Add(-240 * time.Hour)},
+				}},
+				"2": {Name: reporter.SectionCPU, Title: "CPU INFORMATION", Data: &reporter.CPUInfo{
+					Model: "Xeon", Cores: 4, Threads: 8, UsagePercent: usage,
+				}},
+			},
+		}},
+	}
+	if err := reporter.SaveReportToJSON(report, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	dir := cliEnv(t)
+	file := func(name string) string { return filepath.Join(dir, name) }
+
+	accepted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer accepted.Close()
+	rejected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad report", http.StatusBadRequest)
+	}))
+	defer rejected.Close()
+
+	tests := []struct {
+		name   string
+		run    func([]string) int
+		args   []string
+		code   int
+		output string // фрагмент вывода
+	}{
+		{"collect json", runCollect, []string{"-output", file("collected.json")}, exitOK, "Report content hash"},
+		{"collect text", runCollect, []string{"-format", "text", "-output", file("collected.txt")}, exitOK, ""},
+		{"collect unknown format", runCollect, []string{"-format", "xml"}, exitUsage, "xml"},
+		{"collect csv to stdout", runCollect, []string{"-format", "csv", "-output", "-"}, exitUsage, "-output - is not supported"},
+		{"collect format and template", runCollect, []string{"-format", "text", "-template", "motd"}, exitUsage, "mutually exclusive"},
+		{"collect extra argument", runCollect, []string{"extra"}, exitUsage, "unexpected arguments: extra"},
+		{"collect bad override", runCollect, []string{"-set", "workers=many", "-output", file("x.json")}, exitFailure, "Error loading config"},
+		{"collect missing template", runCollect, []string{"-template", file("absent.tmpl")}, exitFailure, "absent.tmpl"},
+
+		{"send accepted", runSend, []string{"-set", "api_base_url=" + accepted.URL, file("old.json")}, exitOK, "successfully sent"},
+		{"send rejected", runSend, []string{"-set", "api_base_url=" + rejected.URL, file("old.json")}, exitFailure, "400"},
+		{"send missing file", runSend, []string{file("absent.json")}, exitFailure, "Error loading report"},
+		{"send without file", runSend, nil, exitUsage, "expected exactly one report file"},
+
+		{"diff identical", runDiff, []string{file("old.json"), file("old.json")}, exitOK, "Reports are identical"},
+		{"diff changed", runDiff, []string{file("old.json"), file("new.json")}, exitFailure, "kernel"},
+		{"diff volatile only", runDiff, []string{file("old.json"), file("old-busy.json")}, exitOK, "Reports are identical"},
+		{"diff volatile with -all", runDiff, []string{"-all", file("old.json"), file("old-busy.json")}, exitFailure, "usage_percent"},
+		{"diff missing file", runDiff, []string{file("old.json"), file("absent.json")}, exitUsage, "Error loading report"},
+		{"diff one file", runDiff, []string{file("old.json")}, exitUsage, "expected two report files"},
+
+		{"validate valid", runValidate, []string{file("old.json"), file("new.json")}, exitOK, "new.json: OK"},
+		{"validate invalid", runValidate, []string{file("old.json"), file("invalid.json")}, exitFailure, "invalid.json: INVALID"},
+		{"validate missing file", runValidate, []string{file("absent.json")}, exitFailure, "INVALID"},
+		{"validate schema", runValidate, []string{"-schema"}, exitOK, `"$schema"`},
+		{"validate without files", runValidate, nil, exitUsage, "expected at least one report file"},
+		{"validate unknown flag", runValidate, []string{"-strict"}, exitUsage, "-strict"},
+
+		{"config show", runConfig, []string{"show"}, exitOK, "# source: " + file("config.yaml")},
+		{"config show override", runConfig, []string{"show", "-set", "workers=3"}, exitOK, "workers: 3"},
+		{"config show bad override", runConfig, []string{"show", "-set", "workers=many"}, exitFailure, "Error loading config"},
+		{"config without subcommand", runConfig, nil, exitUsage, "Usage: reporter config"},
+		{"config unknown subcommand", runConfig, []string{"edit"}, exitUsage, `unknown config subcommand "edit"`},
+		{"config help", runConfig, []string{"-h"}, exitOK, "Usage: reporter config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, output := runCLI(t, tt.run, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code = %d, want %d; output:\n%s", code, tt.code, output)
+			}
+			if !strings.Contains(output, tt.output) {
+				t.Errorf("output does not contain %q:\n%s", tt.output, output)
+			}
+		})
+	}
+}
+
+func TestCommandStdoutWriteError(t *testing.T) {
+	dir := cliEnv(t)
+	// Файл, открытый только на чтение, отвергает запись, как закрытый канал
+	readOnly, err := os.Open(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnly.Close()
+
+	tests := []struct {
+		name   string
+		run    func([]string) int
+		args   []string
+		output string
+	}{
+		{"config show", runConfig, []string{"show"}, "Error writing config"},
+		{"validate schema", runValidate, []string{"-schema"}, "Error writing schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, output := runCLI(t, func(args []string) int {
+				stdout := os.Stdout
+				os.Stdout = readOnly
+				defer func() { os.Stdout = stdout }()
+				return tt.run(args)
+			}, tt.args...)
+			if code != exitFailure {
+				t.Errorf("exit code = %d, want %d", code, exitFailure)
+			}
+			if !strings.Contains(output, tt.output) {
+				t.Errorf("stderr does not contain %q:\n%s", tt.output, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"RPC-report/pkg/reporter"
+)
+
+// runCollect собирает отчет и только записывает его: reporter collect [флаги]
+func runCollect(args []string) int {
+	fs := newFlagSet("collect", "[flags]")
+	format := fs.String("format", "json", "Report format: "+strings.Join(reporter.ExportFormats(), ", "))
+	outputFile := fs.String("output", "", "Output path, - for stdout (default report.json, report.html, report-csv directory; stdout for text and markdown)")
+	colorMode := fs.String("color", "auto", "Highlight text output: auto, always or never")
+	templateName := fs.String("template", "", "Render the report through a text/template file or a bundled template ("+
+		strings.Join(reporter.BundledTemplates(), ", ")+"), output to stdout by default")
+	postmanFlag := fs.Bool("postman", false, "Also generate a Postman request file")
+	curlFlag := fs.Bool("curl", false, "Also generate a curl request file")
+	influxFile := fs.String("influx", "", "Also write metrics in InfluxDB line protocol to this file")
+	graphiteFile := fs.String("graphite", "", "Also write metrics in Graphite plaintext format to this file")
+	var cf configFlags
+	cf.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	formatSet := false
+	fs.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+	if formatSet && *templateName != "" {
+		return usageError(fs, "-format and -template are mutually exclusive")
+	}
+	output, err := outputSink(*format, *outputFile, *colorMode)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	config, err := cf.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return exitFailure
+	}
+
+	// Шаблон из конфигурации действует, если формат не задан явно
+	if *templateName == "" && !formatSet {
+		*templateName = config.Template
+	}
+	if *templateName != "" {
+		tmpl, err := reporter.LoadTemplate(*templateName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitFailure
+		}
+		output = reporter.ExporterSink(reporter.TemplateExporter(tmpl), *outputFile)
+	}
+
+	// Сообщения о ходе работы и ошибки не должны попадать в отчет,
+	// который пишется в stdout
+	toStdout := writesStdout(*format, *outputFile, *templateName != "")
+	if toStdout {
+		reporter.LogOutput = os.Stderr
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	sinks := []reporter.Sink{output}
+	if *templateName == "" && *format == "json" && !toStdout {
+		fmt.Printf("Generating system report for host: %s\n", reporter.GetHostID())
+		sinks = append(sinks, reporter.HashSink(config.HashExcludeFields, os.Stdout))
+	}
+	sinks = append(sinks, requestFileSinks(config, *postmanFlag, *curlFlag)...)
+	if *influxFile != "" {
+		sinks = append(sinks, reporter.InfluxFileSink(*influxFile))
+	}
+	if *graphiteFile != "" {
+		sinks = append(sinks, reporter.GraphiteFileSink(*graphiteFile, config.Graphite.Prefix))
+	}
+
+	if _, err := reporter.NewPipeline(reporter.New(config), sinks...).Run(ctx); err != nil {
+		fmt.Fprintf(reporter.LogOutput, "Error: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// defaultOutputs пути вывода collect по умолчанию; форматов без пути
+// (text, markdown) - stdout
+var defaultOutputs = map[string]string{
+	"json": "report.json",
+	"html": "report.html",
+	"csv":  "report-csv",
+}
+
+// outputSink возвращает приемник основного вывода collect в формате
+// format. Пустой output - путь по умолчанию для формата, "-" - stdout.
+func outputSink(format, output, colorMode string) (reporter.Sink, error) {
+	exporter, err := reporter.ExporterFor(format)
+	if err != nil {
+		return nil, err
+	}
+	switch colorMode {
+	case "auto":
+	case "always", "never":
+		if format == "text" {
+			exporter = reporter.TextExporter(&reporter.TextOptions{Color: colorMode == "always"})
+		}
+	default:
+		return nil, fmt.Errorf("invalid -color %q, use auto, always or never", colorMode)
+	}
+	if output == "" {
+		output = defaultOutputs[format]
+	}
+	if format == "csv" && output == "-" {
+		return nil, fmt.Errorf("-format csv writes a directory, -output - is not supported")
+	}
+	return reporter.ExporterSink(exporter, output), nil
+}
+
+// writesStdout сообщает, пишется ли основной вывод collect в stdout:
+// явный "-" или формат без пути по умолчанию. Вывод шаблона без пути
+// тоже идет в stdout.
+func writesStdout(format, output string, template bool) bool {
+	if output == "" && !template {
+		output = defaultOutputs[format]
+	}
+	return output == "" || output == "-"
+}
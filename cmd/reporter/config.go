@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"RPC-report/pkg/reporter"
+)
+
+// runConfig выполняет reporter config show [флаги]
+func runConfig(args []string) int {
+	fs := newFlagSet("config", "show [flags]")
+	var cf configFlags
+	cf.register(fs)
+	if len(args) == 0 || isHelpFlag(args[0]) {
+		fs.Usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	if args[0] != "show" {
+		return usageError(fs, "unknown config subcommand %q", args[0])
+	}
+	if code, ok := parseFlags(fs, args[1:]); !ok {
+		return code
+	}
+
+	config, err := cf.load()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return exitFailure
+	}
+
+	source := cf.file
+	if source == "" {
+		source = reporter.FindConfigFile()
+	}
+	if source == "" {
+		source = "defaults"
+	}
+	data, err := config.ToYAML()
+	if err != nil {
+		fmt.Printf("Error encoding config: %v\n", err)
+		return exitFailure
+	}
+	fmt.Printf("# source: %s\n", source)
+	if _, err := os.Stdout.Write(data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"RPC-report/pkg/reporter"
+)
+
+// runDiff сравнивает два сохраненных отчета: reporter diff [флаги] <старый> <новый>
+func runDiff(args []string) int {
+	fs := newFlagSet("diff", "[flags] <old.json> <new.json>")
+	all := fs.Bool("all", false, "Also compare volatile fields such as CPU usage and timestamps")
+	var cf configFlags
+	cf.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 2 {
+		return usageError(fs, "expected two report files")
+	}
+
+	var exclude []string
+	if !*all {
+		config, err := cf.load()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			return exitUsage
+		}
+		exclude = config.HashExcludeFields
+	}
+
+	before, err := reporter.LoadReportFromJSON(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Error loading report: %v\n", err)
+		return exitUsage
+	}
+	after, err := reporter.LoadReportFromJSON(fs.Arg(1))
+	if err != nil {
+		fmt.Printf("Error loading report: %v\n", err)
+		return exitUsage
+	}
+
+	changes, err := reporter.DiffReports(before, after, exclude)
+	if err != nil {
+		fmt.Printf("Error comparing reports: %v\n", err)
+		return exitUsage
+	}
+	if len(changes) == 0 {
+		fmt.Println("Reports are identical")
+		return exitOK
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	return exitFailure
+}
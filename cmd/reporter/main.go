@@ -1,186 +1,241 @@
 package main
 
 import (
-	"flag"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 
 	"RPC-report/pkg/reporter"
 )
 
 func main() {
-	// Обработка флагов
-	outputFile := flag.String("output", "report.json", "Output JSON file for report")
-	postmanFlag := flag.Bool("postman", false, "Generate Postman request file")
-	curlFlag := flag.Bool("curl", false, "Generate curl request file")
-	flag.Parse()
+	args := os.Args[1:]
 
-	// Создаем репортер с конфигурацией по умолчанию
-	rep := reporter.New(nil)
+	// Без команды или с флагами прежнего интерфейса выполняется run
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !isHelpFlag(args[0])) {
+		os.Exit(runLegacy(args))
+	}
+	if isHelpFlag(args[0]) || args[0] == "help" {
+		printUsage()
+		os.Exit(exitOK)
+	}
+
+	for _, cmd := range commandList() {
+		if cmd.name == args[0] {
+			os.Exit(cmd.run(args[1:]))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", args[0])
+	printUsage()
+	os.Exit(exitUsage)
+}
+
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// runLegacy прежний сценарий: сбор, сохранение в файл и отправка на API
+func runLegacy(args []string) int {
+	fs := newFlagSet("run", "[flags]")
+	outputFile := fs.String("output", "report.json", "Output JSON file for report")
+	postmanFlag := fs.Bool("postman", false, "Generate Postman request file")
+	curlFlag := fs.Bool("curl", false, "Generate curl request file")
+	var cf configFlags
+	cf.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	config, err := cf.load()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return exitFailure
+	}
+
+	// Прерываем сбор и отправку по SIGINT/SIGTERM
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Создаем репортер с загруженной конфигурацией
+	rep := reporter.New(config)
 
 	// Получаем host_id
 	hostID := reporter.GetHostID()
 	fmt.Printf("Generating system report for host: %s\n", hostID)
 
-	// Генерируем отчет
-	fmt.Println("Generating system report...")
-	report, err := rep.GenerateReport()
-	if err != nil {
-		fmt.Printf("Error generating report: %v\n", err)
-		os.Exit(1)
+	// Собираем отчет один раз: файл, хеш, файлы запросов и API получают один и тот же отчет
+	sinks := []reporter.Sink{
+		reporter.FileSink(*outputFile),
+		reporter.HashSink(config.HashExcludeFields, os.Stdout),
 	}
+	sinks = append(sinks, requestFileSinks(config, *postmanFlag, *curlFlag)...)
+	sinks = append(sinks, rep.Sinks()...)
 
-	// Сохраняем в файл
-	fmt.Println("Saving report to file...")
-	if err := reporter.SaveReportToJSON(report, *outputFile); err != nil {
-		fmt.Printf("Error saving report to file: %v\n", err)
+	if _, err := reporter.NewPipeline(rep, sinks...).Run(ctx); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return exitFailure
 	}
 
-	// Конвертируем отчет для API
-	reportData, err := reporter.ConvertToMap(report)
-	if err != nil {
-		fmt.Printf("Error converting report: %v\n", err)
-		os.Exit(1)
-	}
+	fmt.Printf("Report successfully sent to API for host: %s\n", hostID)
+	fmt.Println("System report completed successfully!")
+	return exitOK
+}
 
-	// Создаем Postman запрос если указан флаг
-	if *postmanFlag {
-		fmt.Println("Generating Postman request...")
-		if err := createPostmanRequest(reportData, "postman_request.json"); err != nil {
-			fmt.Printf("Error creating Postman request: %v\n", err)
-		}
+// requestFileSinks приемники, создающие файлы запросов Postman и curl
+func requestFileSinks(config *reporter.Config, postman, curl bool) []reporter.Sink {
+	var sinks []reporter.Sink
+	if postman {
+		sinks = append(sinks, requestFileSink("postman", config, "postman_request.json", createPostmanRequest))
 	}
+	if curl {
+		sinks = append(sinks, requestFileSink("curl", config, "curl_request.sh", createCurlRequest))
+	}
+	return sinks
+}
 
-	// Создаем curl запрос если указан флаг
-	if *curlFlag {
-		fmt.Println("Generating curl request...")
-		if err := createCurlRequest(reportData, "curl_request.sh"); err != nil {
-			fmt.Printf("Error creating curl request: %v\n", err)
+func requestFileSink(name string, config *reporter.Config, filename string,
+	create func(*reporter.Config, map[string]interface{}, string) error) reporter.Sink {
+	return reporter.NewSink(name, func(ctx context.Context, report *reporter.SystemReport) error {
+		reportData, err := reporter.ConvertToMap(report)
+		if err != nil {
+			return fmt.Errorf("error converting report: %v", err)
 		}
+		return create(config, reportData, filename)
+	})
+}
+
+// Структуры для файла запроса Postman
+type PostmanRequest struct {
+	Name    string             `json:"name"`
+	Request PostmanRequestData `json:"request"`
+}
+
+type PostmanRequestData struct {
+	Method string          `json:"method"`
+	Header []PostmanHeader `json:"header"`
+	Body   PostmanBody     `json:"body"`
+	URL    PostmanURL      `json:"url"`
+}
+
+type PostmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+type PostmanBody struct {
+	Mode string `json:"mode"`
+	Raw  string `json:"raw"`
+}
+
+type PostmanURL struct {
+	Raw  string   `json:"raw"`
+	Host []string `json:"host"`
+	Path []string `json:"path"`
+}
+
+// Функции для создания Postman и curl запросов (можно вынести в отдельный пакет)
+// createPostmanRequest создает файл для Postman
+func createPostmanRequest(config *reporter.Config, reportData map[string]interface{}, filename string) error {
+	// Создаем JSON для тела запроса
+	requestBody := reporter.APIReportRequest{
+		Agent:  config.AgentName,
+		Report: reportData,
 	}
 
-	// Отправляем на API
-	fmt.Println("Sending report to API...")
+	jsonData, err := json.MarshalIndent(requestBody, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %v", err)
+	}
 
-	// Вычисляем хеш отчета
-	hash, err := reporter.CalculateReportHash(report)
+	apiURL := config.APIBaseURL + config.ReportEndpoint
+	parsedURL, err := url.Parse(apiURL)
 	if err != nil {
-		fmt.Printf("Error calculating report hash: %v\n", err)
-	} else {
-		fmt.Printf("Report hash: %s\n", hash)
+		return fmt.Errorf("invalid API URL: %v", err)
 	}
 
-	// Отправляем отчет на API
-	if err := rep.GenerateAndSend(); err != nil {
-		fmt.Printf("Error sending report to API: %v\n", err)
-		fmt.Println("Report was saved locally but failed to send to API")
-		os.Exit(1)
+	// Создаем структуру для Postman
+	postmanRequest := PostmanRequest{
+		Name: "System Report API",
+		Request: PostmanRequestData{
+			Method: "PATCH",
+			Header: []PostmanHeader{
+				{
+					Key:   "Content-Type",
+					Value: "application/json",
+					Type:  "text",
+				},
+			},
+			Body: PostmanBody{
+				Mode: "raw",
+				Raw:  string(jsonData),
+			},
+			URL: PostmanURL{
+				Raw:  apiURL,
+				Host: []string{parsedURL.Host},
+				Path: strings.Split(strings.Trim(parsedURL.Path, "/"), "/"),
+			},
+		},
 	}
 
-	fmt.Printf("Report successfully sent to API for host: %s\n", hostID)
-	fmt.Println("System report completed successfully!")
-	
-	// Выводим информацию о созданных файлах
-	if *postmanFlag {
-		fmt.Printf("Postman request file created: %s\n", "postman_request.json")
+	// Сохраняем в файл
+	postmanData, err := json.MarshalIndent(postmanRequest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal postman request: %v", err)
 	}
-	if *curlFlag {
-		fmt.Printf("Curl request file created: %s\n", "curl_request.sh")
+
+	err = os.WriteFile(filename, postmanData, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write postman file: %v", err)
 	}
-}
 
-// Функции для создания Postman и curl запросов (можно вынести в отдельный пакет)
-// ... (остальной код функций createPostmanRequest и createCurlRequest)
-// createPostmanRequest создает файл для Postman
-func createPostmanRequest(reportData map[string]interface{}, filename string) error {
-        // Создаем JSON для тела запроса
-        requestBody := APIReportRequest{
-                Agent:  "system-reporter",
-                Report: reportData,
-        }
-
-        jsonData, err := json.MarshalIndent(requestBody, "", "  ")
-        if err != nil {
-                return fmt.Errorf("failed to marshal request body: %v", err)
-        }
-
-        // Создаем структуру для Postman
-        postmanRequest := PostmanRequest{
-                Name: "System Report API",
-                Request: PostmanRequestData{
-                        Method: "PATCH",
-                        Header: []PostmanHeader{
-                                {
-                                        Key:   "Content-Type",
-                                        Value: "application/json",
-                                        Type:  "text",
-                                },
-                        },
-                        Body: PostmanBody{
-                                Mode: "raw",
-                                Raw:  string(jsonData),
-                        },
-                        URL: PostmanURL{
-                                Raw:  APIBaseURL + ReportEndpoint,
-                                Host: []string{"31.28.27.171:8123"},
-                                Path: []string{"api", "report"},
-                        },
-                },
-        }
-
-        // Сохраняем в файл
-        postmanData, err := json.MarshalIndent(postmanRequest, "", "  ")
-        if err != nil {
-                return fmt.Errorf("failed to marshal postman request: %v", err)
-        }
-
-        err = os.WriteFile(filename, postmanData, 0644)
-        if err != nil {
-                return fmt.Errorf("failed to write postman file: %v", err)
-        }
-
-        fmt.Printf("Postman request saved to %s (%d bytes)\n", filename, len(postmanData))
-        return nil
+	fmt.Fprintf(reporter.LogOutput, "Postman request saved to %s (%d bytes)\n", filename, len(postmanData))
+	return nil
 }
 
 // createCurlRequest создает файл с curl запросом
-func createCurlRequest(reportData map[string]interface{}, filename string) error {
-        // Создаем JSON для тела запроса
-        requestBody := APIReportRequest{
-                Agent:  "system-reporter",
-                Report: reportData,
-        }
-
-        jsonData, err := json.Marshal(requestBody)
-        if err != nil {
-                return fmt.Errorf("failed to marshal request body: %v", err)
-        }
-
-        // Экранируем JSON для использования в curl
-        escapedJSON := strings.ReplaceAll(string(jsonData), `"`, `\"`)
-        escapedJSON = strings.ReplaceAll(escapedJSON, "`", "\\`")
-        escapedJSON = strings.ReplaceAll(escapedJSON, "$", "\\$")
-
-        // Создаем curl команду
-        curlCommand := fmt.Sprintf(`curl -X PATCH "%s" \
+func createCurlRequest(config *reporter.Config, reportData map[string]interface{}, filename string) error {
+	// Создаем JSON для тела запроса
+	requestBody := reporter.APIReportRequest{
+		Agent:  config.AgentName,
+		Report: reportData,
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	// Экранируем JSON для использования в curl
+	escapedJSON := strings.ReplaceAll(string(jsonData), `"`, `\"`)
+	escapedJSON = strings.ReplaceAll(escapedJSON, "`", "\\`")
+	escapedJSON = strings.ReplaceAll(escapedJSON, "$", "\\$")
+
+	// Создаем curl команду
+	curlCommand := fmt.Sprintf(`curl -X PATCH "%s" \
   -H "Content-Type: application/json" \
-  -d "%s"`, APIBaseURL+ReportEndpoint, escapedJSON)
+  -d "%s"`, config.APIBaseURL+config.ReportEndpoint, escapedJSON)
 
-        // Альтернативный вариант с @filename (более надежный для больших JSON)
-        curlCommandAlt := fmt.Sprintf(`# Альтернативный вариант с файлом (рекомендуется для больших JSON):
+	// Альтернативный вариант с @filename (более надежный для больших JSON)
+	curlCommandAlt := fmt.Sprintf(`# Альтернативный вариант с файлом (рекомендуется для больших JSON):
 echo '%s' | curl -X PATCH "%s" \
   -H "Content-Type: application/json" \
-  -d @-`, string(jsonData), APIBaseURL+ReportEndpoint)
+  -d @-`, string(jsonData), config.APIBaseURL+config.ReportEndpoint)
 
-        // Сохраняем в файл
-        content := fmt.Sprintf("#!/bin/bash\n\n# Curl command for system report API\n# Host ID: %s\n\n%s\n\n%s\n", getHostID(), curlCommand, curlCommandAlt)
+	// Сохраняем в файл
+	content := fmt.Sprintf("#!/bin/bash\n\n# Curl command for system report API\n# Host ID: %s\n\n%s\n\n%s\n", reporter.GetHostID(), curlCommand, curlCommandAlt)
 
-        err = os.WriteFile(filename, []byte(content), 0755)
-        if err != nil {
-                return fmt.Errorf("failed to write curl file: %v", err)
-        }
+	err = os.WriteFile(filename, []byte(content), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to write curl file: %v", err)
+	}
 
-        fmt.Printf("Curl request saved to %s (%d bytes)\n", filename, len(content))
-        return nil
+	fmt.Fprintf(reporter.LogOutput, "Curl request saved to %s (%d bytes)\n", filename, len(content))
+	return nil
 }
-
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"RPC-report/pkg/reporter"
+)
+
+// runSend отправляет сохраненный отчет: reporter send [флаги] <файл>
+func runSend(args []string) int {
+	fs := newFlagSet("send", "[flags] <report.json>")
+	var cf configFlags
+	cf.register(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "expected exactly one report file")
+	}
+
+	config, err := cf.load()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return exitFailure
+	}
+
+	report, err := reporter.LoadReportFromJSON(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Error loading report: %v\n", err)
+		return exitFailure
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	// Отчет проходит тот же путь, что и собранный: очередь, повторы, дельта
+	rep := reporter.New(config)
+	sinks := append([]reporter.Sink{reporter.HashSink(config.HashExcludeFields, os.Stdout)}, rep.Sinks()...)
+	pipeline := reporter.NewPipeline(rep, sinks...)
+	if err := pipeline.Dispatch(ctx, report); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return exitFailure
+	}
+	fmt.Printf("Report %s successfully sent to API\n", fs.Arg(0))
+	return exitOK
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"RPC-report/pkg/reporter"
+)
+
+// runValidate проверяет сохраненные отчеты по JSON Schema:
+// reporter validate <файл>... или reporter validate -schema
+func runValidate(args []string) int {
+	fs := newFlagSet("validate", "[-schema] <report.json>...")
+	printSchema := fs.Bool("schema", false, "Print the report JSON Schema and exit")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if *printSchema {
+		if _, err := os.Stdout.Write(reporter.ReportSchema()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing schema: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	if fs.NArg() == 0 {
+		return usageError(fs, "expected at least one report file")
+	}
+
+	code := exitOK
+	for _, file := range fs.Args() {
+		problems := checkReportFile(file)
+		if len(problems) == 0 {
+			fmt.Printf("%s: OK\n", file)
+			continue
+		}
+		code = exitFailure
+		fmt.Printf("%s: INVALID\n", file)
+		for _, problem := range problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+	return code
+}
+
+// checkReportFile проверяет файл по схеме отчета (reporter.Validate) и
+// возвращает нарушения в виде "JSON Pointer: описание"
+func checkReportFile(file string) []string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	err = reporter.Validate(data)
+	var validationErr *reporter.ValidationError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &validationErr):
+		problems := make([]string, len(validationErr.Violations))
+		for i, v := range validationErr.Violations {
+			problems[i] = v.String()
+		}
+		return problems
+	}
+	return []string{err.Error()}
+}
@@ -2,7 +2,12 @@ module RPC-report
 
 go 1.24.5
 
-require github.com/shirou/gopsutil/v4 v4.25.10
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/klauspost/compress v1.18.0
+	github.com/shirou/gopsutil/v4 v4.25.10
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/ebitengine/purego v0.9.0 // indirect
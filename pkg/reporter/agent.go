@@ -0,0 +1,118 @@
+package reporter
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Интервалы агента по умолчанию
+const (
+	DefaultInterval = 5 * time.Minute
+	DefaultJitter   = 30 * time.Second
+)
+
+// Run периодически собирает и отправляет отчеты до отмены ctx.
+// Первый отчет отправляется сразу, следующие - через Config.Interval
+// со случайной добавкой до Config.Jitter, чтобы агенты парка не
+// обращались к серверу одновременно. Ошибки цикла не прерывают работу.
+// При отмене ctx текущий цикл прерывается, и Run возвращает nil.
+// Если задан Config.MetricsListen, последний отчет доступен на /metrics;
+// при смене адреса через SetConfig сервер метрик перезапускается.
+func (r *Reporter) Run(ctx context.Context) error {
+	var metrics metricsServer
+	defer metrics.close()
+	metrics.update(ctx, r)
+
+	for {
+		start := time.Now()
+		if err := r.GenerateAndSendContext(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			logf("Report cycle failed: %v\n", err)
+		} else {
+			logf("Report cycle completed in %s\n", time.Since(start).Round(time.Millisecond))
+		}
+
+		delay := nextRunDelay(r.GetConfig())
+		logf("Next report in %s\n", delay.Round(time.Second))
+		if !r.sleep(ctx, delay, &metrics) {
+			return nil
+		}
+	}
+}
+
+// sleep ждет delay; конфигурация, замененная за это время, сразу
+// применяется к серверу метрик. Возвращает false при отмене ctx.
+func (r *Reporter) sleep(ctx context.Context, delay time.Duration, metrics *metricsServer) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-r.reloaded:
+			metrics.update(ctx, r)
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// metricsServer сервер /metrics агента на адресе Config.MetricsListen
+type metricsServer struct {
+	addr string
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// update запускает, перезапускает или останавливает сервер, если адрес
+// в конфигурации отличается от текущего
+func (m *metricsServer) update(ctx context.Context, r *Reporter) {
+	addr := r.GetConfig().MetricsListen
+	if addr == m.addr {
+		return
+	}
+	if m.stop != nil {
+		logf("Stopping metrics on %s/metrics\n", m.addr)
+	}
+	m.close()
+	m.addr = addr
+	if addr == "" {
+		return
+	}
+
+	serveCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	m.stop, m.done = stop, done
+	go func() {
+		defer close(done)
+		logf("Serving metrics on %s/metrics\n", addr)
+		if err := r.ServeMetrics(serveCtx, addr); err != nil {
+			logf("Warning: %v\n", err)
+		}
+	}()
+}
+
+// close останавливает сервер и ждет, пока он освободит адрес
+func (m *metricsServer) close() {
+	if m.stop == nil {
+		return
+	}
+	m.stop()
+	<-m.done
+	m.stop, m.done = nil, nil
+}
+
+// nextRunDelay возвращает паузу до следующего цикла с учетом jitter
+func nextRunDelay(config *Config) time.Duration {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if config.Jitter > 0 {
+		interval += rand.N(config.Jitter)
+	}
+	return interval
+}
@@ -0,0 +1,163 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNextRunDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		jitter   time.Duration
+		min, max time.Duration // max не включается
+	}{
+		{"interval with jitter", time.Minute, 10 * time.Second, time.Minute, time.Minute + 10*time.Second},
+		{"no jitter", time.Minute, 0, time.Minute, time.Minute + 1},
+		{"negative jitter ignored", time.Minute, -time.Second, time.Minute, time.Minute + 1},
+		{"default interval", 0, time.Second, DefaultInterval, DefaultInterval + time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Interval: tt.interval, Jitter: tt.jitter}
+			for i := 0; i < 1000; i++ {
+				if got := nextRunDelay(config); got < tt.min || got >= tt.max {
+					t.Fatalf("nextRunDelay = %s, want in [%s, %s)", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+// agentReporter возвращает репортер с одной тестовой секцией, который
+// отправляет отчеты на url без очереди и повторов
+func agentReporter(t *testing.T, url string, interval time.Duration) *Reporter {
+	t.Helper()
+	config := metricsTestConfig()
+	config.APIBaseURL = url
+	config.SpoolDir = ""
+	config.Interval, config.Jitter = interval, 0
+
+	r := New(config)
+	for _, c := range r.Collectors() {
+		r.Unregister(c.Name())
+	}
+	if err := r.Register(NewCollector("probe", "PROBE", func(ctx context.Context) (interface{}, error) { return "ok", nil })); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// reportNumbers принимает отчеты и передает их номера в канал
+func reportNumbers(t *testing.T) (*httptest.Server, <-chan int) {
+	numbers := make(chan int, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Report struct {
+				Reports []struct {
+					ReportNumber int `json:"report_number"`
+				} `json:"reports"`
+			} `json:"report"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Report.Reports) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		numbers <- request.Report.Reports[0].ReportNumber
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, numbers
+}
+
+// startAgent запускает Run в фоне и возвращает функцию остановки,
+// которая ждет завершения Run и возвращает его результат
+func startAgent(t *testing.T, r *Reporter) func() error {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+	t.Cleanup(cancel)
+	return func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after cancel")
+			return nil
+		}
+	}
+}
+
+func TestRunFirstCycleImmediately(t *testing.T) {
+	discardLog(t)
+	server, numbers := reportNumbers(t)
+	// Интервал больше времени теста: дождаться можно только первого цикла
+	stop := startAgent(t, agentReporter(t, server.URL, time.Hour))
+
+	select {
+	case n := <-numbers:
+		if n != 1 {
+			t.Errorf("first report_number = %d, want 1", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("first report was not sent immediately")
+	}
+	if err := stop(); err != nil {
+		t.Errorf("Run = %v, want nil", err)
+	}
+	if len(numbers) != 0 {
+		t.Errorf("%d extra reports sent before interval elapsed", len(numbers))
+	}
+}
+
+func TestRunReportNumberIncrements(t *testing.T) {
+	discardLog(t)
+	server, numbers := reportNumbers(t)
+	stop := startAgent(t, agentReporter(t, server.URL, 10*time.Millisecond))
+
+	for want := 1; want <= 3; want++ {
+		select {
+		case n := <-numbers:
+			if n != want {
+				t.Errorf("report_number = %d, want %d", n, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("report %d was not sent", want)
+		}
+	}
+	if err := stop(); err != nil {
+		t.Errorf("Run = %v, want nil", err)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	discardLog(t)
+	// Сервер отвечает только после отмены: Run должен прервать текущий цикл
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	r := agentReporter(t, server.URL, time.Hour)
+	stop := startAgent(t, r)
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if err := stop(); err != nil {
+		t.Errorf("Run = %v, want nil", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Run returned %s after cancel", elapsed)
+	}
+}
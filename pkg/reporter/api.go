@@ -2,6 +2,7 @@ package reporter
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -19,72 +20,176 @@ func DefaultConfig() *Config {
 		ReportEndpoint: "/report",
 		Timeout:        60 * time.Second,
 		AgentName:      "system-reporter",
+
+		Workers:          defaultWorkers,
+		CollectorTimeout: 15 * time.Second,
+		ReportTimeout:    45 * time.Second,
+
+		DockerSocket: DefaultDockerSocket,
+		Security:     DefaultSecurityConfig(),
+
+		Interval: DefaultInterval,
+		Jitter:   DefaultJitter,
+
+		SpoolDir:      DefaultSpoolDir(),
+		SpoolMaxBytes: DefaultSpoolMaxBytes,
+		SpoolMaxAge:   DefaultSpoolMaxAge,
+
+		Retry: DefaultRetryConfig(),
+		TLS:   TLSConfig{MinVersion: "1.2"},
+		OTLP:  DefaultOTLPConfig(),
+
+		Graphite: GraphiteConfig{Prefix: DefaultGraphitePrefix},
+
+		Compression:          CompressionNone,
+		CompressionThreshold: DefaultCompressionThreshold,
+
+		HashExcludeFields: DefaultHashExcludeFields,
+
+		StateFile:          DefaultStateFile(),
+		FullResyncEvery:    DefaultFullResyncEvery,
+		FullResyncInterval: DefaultFullResyncInterval,
 	}
 }
 
 // SendReportToAPI отправляет отчет на API
 func SendReportToAPI(config *Config, reportData map[string]interface{}) error {
-	request := APIReportRequest{
+	return SendReportToAPIContext(context.Background(), config, reportData)
+}
+
+// SendReportToAPIContext отправляет отчет на API с учетом отмены ctx
+func SendReportToAPIContext(ctx context.Context, config *Config, reportData map[string]interface{}) error {
+	request := &APIReportRequest{
 		Agent:  config.AgentName,
 		Report: reportData,
 	}
+	_, err := sendRequest(ctx, config, request)
+	return err
+}
 
+// sendResult сведения из ответа сервера на успешную отправку
+type sendResult struct {
+	resync bool // Сервер просит прислать следующий отчет целиком
+}
+
+// sendRequest отправляет подготовленный запрос на API с повторами
+func sendRequest(ctx context.Context, config *Config, request *APIReportRequest) (sendResult, error) {
+	var result sendResult
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return fmt.Errorf("failed to marshal report: %v", err)
+		return result, fmt.Errorf("failed to marshal report: %v", err)
+	}
+
+	threshold := config.CompressionThreshold
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+	body, encoding, err := compressBody(config.Compression, threshold, jsonData)
+	if err != nil {
+		return result, err
 	}
+	payload := apiPayload{body: body, encoding: encoding}
 
-	fmt.Printf("Sending report to API (%d bytes)...\n", len(jsonData))
+	if encoding != "" {
+		logf("Sending report to API (%d bytes, %s %d bytes)...\n", len(jsonData), encoding, len(body))
+	} else {
+		logf("Sending report to API (%d bytes)...\n", len(jsonData))
+	}
+
+	creds, err := loadCredentials(config)
+	if err != nil {
+		return result, err
+	}
 
-	client := &http.Client{Timeout: config.Timeout}
 	apiURL := config.APIBaseURL + config.ReportEndpoint
+	client, err := newHTTPClient(config.TLS, config.Timeout, apiURL)
+	if err != nil {
+		return result, err
+	}
 
+	err = sendWithRetry(ctx, config.Retry, breakerFor(apiURL), func() error {
+		resync, err := sendOnce(ctx, client, apiURL, payload, creds)
+		result.resync = resync
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	logf("Report successfully sent to API\n")
+	return result, nil
+}
+
+// sendOnce выполняет одну попытку отправки: PATCH, а если сервер его
+// не поддерживает - PUT. Ответ не 2xx возвращается как *APIError.
+// Возвращает true, если сервер запросил полную пересылку заголовком
+// X-Reporter-Resync: full.
+func sendOnce(ctx context.Context, client *http.Client, apiURL string, payload apiPayload, creds credentials) (bool, error) {
 	// Сначала пробуем PATCH
-	req, err := http.NewRequest("PATCH", apiURL, bytes.NewBuffer(jsonData))
+	req, err := newAPIRequest(ctx, "PATCH", apiURL, payload, creds)
 	if err != nil {
-		return fmt.Errorf("failed to create PATCH request: %v", err)
+		return false, fmt.Errorf("failed to create PATCH request: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send PATCH request: %v", err)
+		return false, fmt.Errorf("failed to send PATCH request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Если PATCH не поддерживается, пробуем PUT
 	if resp.StatusCode == http.StatusMethodNotAllowed {
-		fmt.Println("PATCH not supported, trying PUT...")
-		req, err = http.NewRequest("PUT", apiURL, bytes.NewBuffer(jsonData))
+		logf("PATCH not supported, trying PUT...\n")
+		req, err = newAPIRequest(ctx, "PUT", apiURL, payload, creds)
 		if err != nil {
-			return fmt.Errorf("failed to create PUT request: %v", err)
+			return false, fmt.Errorf("failed to create PUT request: %v", err)
 		}
-		req.Header.Set("Content-Type", "application/json")
 
 		resp, err = client.Do(req)
 		if err != nil {
-			return fmt.Errorf("failed to send PUT request: %v", err)
+			return false, fmt.Errorf("failed to send PUT request: %w", err)
 		}
 		defer resp.Body.Close()
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("API returned status: %d", resp.StatusCode)
+		return false, newAPIError(resp)
 	}
+	return strings.EqualFold(resp.Header.Get(HeaderResync), "full"), nil
+}
 
-	fmt.Println("Report successfully sent to API")
-	return nil
+// apiPayload тело запроса в том виде, в каком оно уходит в сеть
+type apiPayload struct {
+	body     []byte
+	encoding string // Content-Encoding, пусто для несжатого тела
 }
 
-// CalculateReportHash вычисляет хеш отчета для идентификации
-func CalculateReportHash(report *SystemReport) (string, error) {
-	jsonData, err := json.Marshal(report)
+// newAPIRequest создает запрос к API с заголовками и учетными данными.
+// Подпись вычисляется по передаваемым (возможно сжатым) байтам.
+func newAPIRequest(ctx context.Context, method, apiURL string, payload apiPayload, creds credentials) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, bytes.NewReader(payload.body))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	if payload.encoding != "" {
+		req.Header.Set("Content-Encoding", payload.encoding)
+	}
+	creds.apply(req, payload.body, time.Now())
+	return req, nil
+}
+
+// CalculateReportHash вычисляет хеш отчета для идентификации.
+// Хеш включает все поля, в том числе время генерации; для сравнения
+// содержимого отчетов используйте ContentHash.
+func CalculateReportHash(report *SystemReport) (string, error) {
+	return ContentHash(report, nil)
+}
 
-	hash := sha256.Sum256(jsonData)
-	return hex.EncodeToString(hash[:]), nil
+// sha256Hex возвращает SHA-256 данных в шестнадцатеричном виде
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
 }
 
 // ConvertToMap конвертирует SystemReport в map для API
@@ -114,6 +219,21 @@ func SaveReportToJSON(report *SystemReport, filename string) error {
 		return fmt.Errorf("failed to write file: %v", err)
 	}
 
-	fmt.Printf("Report saved to %s (%d bytes)\n", filename, len(jsonData))
+	logf("Report saved to %s (%d bytes)\n", filename, len(jsonData))
 	return nil
 }
+
+// LoadReportFromJSON читает отчет, сохраненный SaveReportToJSON.
+// Данные секций загружаются как map[string]interface{}.
+func LoadReportFromJSON(filename string) (*SystemReport, error) {
+	jsonData, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	var report SystemReport
+	if err := json.Unmarshal(jsonData, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %v", filename, err)
+	}
+	return &report, nil
+}
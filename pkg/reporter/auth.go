@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Переменные окружения с учетными данными, если они не заданы в Config
+const (
+	EnvAuthToken  = "REPORTER_AUTH_TOKEN"
+	EnvHMACSecret = "REPORTER_HMAC_SECRET"
+)
+
+// Заголовки подписи тела запроса
+const (
+	HeaderTimestamp     = "X-Reporter-Timestamp"
+	HeaderContentSHA256 = "X-Reporter-Content-SHA256"
+	HeaderSignature     = "X-Reporter-Signature"
+)
+
+// credentials учетные данные для запросов к API
+type credentials struct {
+	token   string
+	hmacKey []byte
+}
+
+// loadCredentials собирает учетные данные из Config, файлов и окружения.
+// Приоритет: значение в Config, затем файл, затем переменная окружения.
+func loadCredentials(config *Config) (credentials, error) {
+	token, err := resolveSecret(config.AuthToken, config.AuthTokenFile, EnvAuthToken)
+	if err != nil {
+		return credentials{}, fmt.Errorf("failed to load auth token: %v", err)
+	}
+	secret, err := resolveSecret(config.HMACSecret, config.HMACSecretFile, EnvHMACSecret)
+	if err != nil {
+		return credentials{}, fmt.Errorf("failed to load HMAC secret: %v", err)
+	}
+
+	creds := credentials{token: token}
+	if secret != "" {
+		creds.hmacKey = []byte(secret)
+	}
+	return creds, nil
+}
+
+func resolveSecret(value, file, env string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return os.Getenv(env), nil
+}
+
+// apply добавляет к запросу Bearer-токен и подпись тела
+func (c credentials) apply(req *http.Request, body []byte, now time.Time) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if len(c.hmacKey) == 0 {
+		return
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderContentSHA256, sha256Hex(body))
+	req.Header.Set(HeaderSignature, "sha256="+SignBody(c.hmacKey, timestamp, body))
+}
+
+// SignBody вычисляет HMAC-SHA256 над строкой "<timestamp>.<sha256 тела>".
+// Метка времени входит в подпись, поэтому получатель может отвергать
+// повторно присланные запросы со старой меткой.
+func SignBody(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "." + sha256Hex(body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature проверяет подпись запроса на стороне получателя:
+// метка времени не старше maxSkew и HMAC совпадает с заголовком
+func VerifySignature(secret []byte, header http.Header, body []byte, maxSkew time.Duration, now time.Time) error {
+	timestamp := header.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header", HeaderTimestamp)
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); math.Abs(float64(skew)) > float64(maxSkew) {
+		return fmt.Errorf("timestamp is outside the allowed window of %s", maxSkew)
+	}
+
+	signature, ok := strings.CutPrefix(header.Get(HeaderSignature), "sha256=")
+	if !ok {
+		return fmt.Errorf("missing or malformed %s header", HeaderSignature)
+	}
+	expected := SignBody(secret, timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadCredentialsPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		file    string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{name: "config wins", value: "from-config", file: tokenFile, env: "from-env", want: "from-config"},
+		{name: "file over env", file: tokenFile, env: "from-env", want: "from-file"},
+		{name: "env", env: "from-env", want: "from-env"},
+		{name: "none"},
+		{name: "missing file", file: filepath.Join(dir, "absent"), env: "from-env", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvAuthToken, tt.env)
+			t.Setenv(EnvHMACSecret, tt.env)
+			config := &Config{AuthToken: tt.value, AuthTokenFile: tt.file, HMACSecret: tt.value, HMACSecretFile: tt.file}
+
+			creds, err := loadCredentials(config)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "failed to load auth token") {
+					t.Fatalf("error = %v, want auth token error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds.token != tt.want || string(creds.hmacKey) != tt.want {
+				t.Errorf("token = %q, secret = %q, want %q", creds.token, creds.hmacKey, tt.want)
+			}
+		})
+	}
+}
+
+// signedHeader возвращает заголовки запроса, подписанного в момент now
+func signedHeader(secret string, body []byte, now time.Time) http.Header {
+	req := httptest.NewRequest("PATCH", "/report", nil)
+	credentials{hmacKey: []byte(secret)}.apply(req, body, now)
+	return req.Header
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"agent":"system-reporter"}`)
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    []byte
+		wantErr string
+	}{
+		{name: "valid", header: signedHeader("s3cr3t", body, now)},
+		{name: "clock skew within window", header: signedHeader("s3cr3t", body, now.Add(-4*time.Minute))},
+		{name: "stale timestamp", header: signedHeader("s3cr3t", body, now.Add(-6*time.Minute)), wantErr: "outside the allowed window"},
+		{name: "future timestamp", header: signedHeader("s3cr3t", body, now.Add(6*time.Minute)), wantErr: "outside the allowed window"},
+		{name: "tampered body", header: signedHeader("s3cr3t", body, now), body: []byte(`{"agent":"intruder"}`), wantErr: "signature mismatch"},
+		{name: "wrong secret", header: signedHeader("other", body, now), wantErr: "signature mismatch"},
+		{name: "no signature", header: http.Header{HeaderTimestamp: {"1710072000"}}, wantErr: "missing or malformed"},
+		{name: "bad timestamp", header: http.Header{HeaderTimestamp: {"yesterday"}}, wantErr: "invalid X-Reporter-Timestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := body
+			if tt.body != nil {
+				received = tt.body
+			}
+			err := VerifySignature([]byte("s3cr3t"), tt.header, received, 5*time.Minute, now)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("VerifySignature: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// signedReceiver принимает отчеты и запоминает заголовки и тело как есть
+type signedReceiver struct {
+	mu     sync.Mutex
+	header http.Header
+	body   []byte
+}
+
+func (rcv *signedReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.header = r.Header.Clone()
+	rcv.body, _ = io.ReadAll(r.Body)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestSendRequestSigned(t *testing.T) {
+	discardLog(t)
+	t.Setenv(EnvAuthToken, "")
+	t.Setenv(EnvHMACSecret, "")
+
+	tests := []struct {
+		name        string
+		compression string
+	}{
+		{"plain body", CompressionNone},
+		{"compressed body", CompressionGzip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &signedReceiver{}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			config := metricsTestConfig()
+			config.APIBaseURL = server.URL
+			config.AuthToken = "t0ken"
+			config.HMACSecret = "s3cr3t"
+			config.Compression = tt.compression
+			config.CompressionThreshold = 1
+			request := &APIReportRequest{Agent: "system-reporter", Report: map[string]interface{}{"note": strings.Repeat("a", 4096)}}
+			if _, err := sendRequest(context.Background(), config, request); err != nil {
+				t.Fatalf("sendRequest: %v", err)
+			}
+
+			receiver.mu.Lock()
+			defer receiver.mu.Unlock()
+			if got := receiver.header.Get("Authorization"); got != "Bearer t0ken" {
+				t.Errorf("Authorization = %q, want %q", got, "Bearer t0ken")
+			}
+			// Подпись проверяется по байтам, пришедшим по сети, до распаковки
+			if err := VerifySignature([]byte("s3cr3t"), receiver.header, receiver.body, time.Minute, time.Now()); err != nil {
+				t.Errorf("VerifySignature over received bytes: %v", err)
+			}
+			if got := receiver.header.Get(HeaderContentSHA256); got != sha256Hex(receiver.body) {
+				t.Errorf("%s = %s, want hash of received body", HeaderContentSHA256, got)
+			}
+
+			if tt.compression == CompressionNone {
+				return
+			}
+			if got := receiver.header.Get("Content-Encoding"); got != tt.compression {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.compression)
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(receiver.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			plain, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if VerifySignature([]byte("s3cr3t"), receiver.header, plain, time.Minute, time.Now()) == nil {
+				t.Error("signature also matches the uncompressed body")
+			}
+		})
+	}
+}
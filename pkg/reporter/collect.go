@@ -0,0 +1,210 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Статусы секций, данные которых не удалось получить
+const (
+	SectionStatusError       = "error"
+	SectionStatusTimeout     = "timeout"
+	SectionStatusUnavailable = "unavailable"
+)
+
+// ErrUnavailable возвращается сборщиком, если источник данных отсутствует
+// на хосте (например, не установлен Docker); секция помечается "unavailable"
+var ErrUnavailable = errors.New("source unavailable")
+
+// defaultWorkers используется, если Config.Workers не задан
+const defaultWorkers = 4
+
+// collectOptions ограничения параллельного сбора секций
+type collectOptions struct {
+	workers          int
+	collectorTimeout time.Duration
+	reportTimeout    time.Duration
+}
+
+func newCollectOptions(config *Config) collectOptions {
+	opts := collectOptions{
+		workers:          config.Workers,
+		collectorTimeout: config.CollectorTimeout,
+		reportTimeout:    config.ReportTimeout,
+	}
+	if opts.workers <= 0 {
+		opts.workers = defaultWorkers
+	}
+	return opts
+}
+
+// collectResult результат работы одного сборщика
+type collectResult struct {
+	data interface{}
+	err  error
+}
+
+// collectSections параллельно собирает секции пулом из opts.workers горутин.
+// Сборщик, не уложившийся в collectorTimeout, и все секции, не собранные
+// к истечению reportTimeout, попадают в отчет со статусом "timeout".
+// Отмена самого ctx прерывает сбор с ошибкой.
+func collectSections(ctx context.Context, collectors []Collector, opts collectOptions) (map[string]Section, error) {
+	sections := make(map[string]Section, len(collectors))
+	if len(collectors) == 0 {
+		return sections, nil
+	}
+
+	reportCtx, cancel := withOptionalTimeout(ctx, opts.reportTimeout)
+	defer cancel()
+
+	type indexedResult struct {
+		index int
+		collectResult
+	}
+
+	jobs := make(chan int)
+	results := make(chan indexedResult, len(collectors))
+
+	workers := min(opts.workers, len(collectors))
+	for range workers {
+		go func() {
+			for i := range jobs {
+				results <- indexedResult{index: i, collectResult: runCollector(reportCtx, collectors[i], opts.collectorTimeout)}
+			}
+		}()
+	}
+
+	// Раздаем задания, пока не истек общий срок отчета
+	go func() {
+		defer close(jobs)
+		for i := range collectors {
+			select {
+			case jobs <- i:
+			case <-reportCtx.Done():
+				return
+			}
+		}
+	}()
+
+	done := make([]bool, len(collectors))
+	for received := 0; received < len(collectors); received++ {
+		select {
+		case res := <-results:
+			done[res.index] = true
+			sections[sectionKey(collectors[res.index].Name())] = newSection(collectors[res.index], res.collectResult)
+		case <-reportCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for i, c := range collectors {
+				if !done[i] {
+					err := fmt.Errorf("report deadline of %s exceeded", opts.reportTimeout)
+					sections[sectionKey(c.Name())] = newSection(c, collectResult{err: &timeoutError{err: err}})
+				}
+			}
+			// Зависшие сборщики дописывают результат в буферизованный канал
+			// и завершаются сами, дожидаться их не нужно
+			return sections, nil
+		}
+	}
+
+	return sections, nil
+}
+
+// runCollector запускает сборщик и ждет его не дольше timeout.
+// Сборщик получает контекст со сроком; если он его не соблюдает,
+// результат отбрасывается по истечении срока.
+func runCollector(ctx context.Context, c Collector, timeout time.Duration) collectResult {
+	collectCtx, cancel := withOptionalTimeout(ctx, timeout)
+	defer cancel()
+
+	resultCh := make(chan collectResult, 1)
+	go func() {
+		data, err := c.Collect(collectCtx)
+		resultCh <- collectResult{data: data, err: err}
+	}()
+
+	select {
+	case res := <-resultCh:
+		if res.err != nil && errors.Is(collectCtx.Err(), context.DeadlineExceeded) {
+			res.err = &timeoutError{err: res.err}
+		}
+		return res
+	case <-collectCtx.Done():
+		if errors.Is(collectCtx.Err(), context.DeadlineExceeded) {
+			err := fmt.Errorf("collector timed out")
+			if timeout > 0 {
+				err = fmt.Errorf("collector timed out after %s", timeout)
+			}
+			return collectResult{err: &timeoutError{err: err}}
+		}
+		return collectResult{err: collectCtx.Err()}
+	}
+}
+
+// withOptionalTimeout добавляет к ctx срок, если timeout задан
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError помечает ошибку превышения срока сбора секции
+type timeoutError struct {
+	err error
+}
+
+func (e *timeoutError) Error() string { return e.err.Error() }
+
+// newSection формирует секцию отчета по результату сборщика
+func newSection(c Collector, res collectResult) Section {
+	section := Section{
+		Name:  c.Name(),
+		Title: c.Title(),
+		Data:  res.data,
+	}
+	if res.err != nil {
+		section.Data = nil
+		section.Error = res.err.Error()
+
+		var timeoutErr *timeoutError
+		switch {
+		case errors.Is(res.err, ErrUnavailable):
+			// Отсутствие источника - штатная ситуация, без предупреждения
+			section.Status = SectionStatusUnavailable
+			return section
+		case errors.As(res.err, &timeoutErr):
+			section.Status = SectionStatusTimeout
+		default:
+			section.Status = SectionStatusError
+		}
+		logf("Warning: failed to get %s: %v\n", c.Title(), res.err)
+	}
+	return section
+}
+
+// builtinSectionKeys постоянные ключи встроенных секций. Номера не
+// зависят от того, какие сборщики отключены или переставлены, поэтому
+// секция сохраняет ключ между отчетами.
+var builtinSectionKeys = map[string]string{
+	SectionHost:      "1",
+	SectionCPU:       "2",
+	SectionMemory:    "3",
+	SectionDisk:      "4",
+	SectionNetwork:   "5",
+	SectionProcesses: "6",
+	SectionDocker:    "7",
+	SectionSecurity:  "8",
+}
+
+// sectionKey возвращает ключ секции сборщика name: постоянный номер для
+// встроенных секций, имя сборщика для остальных
+func sectionKey(name string) string {
+	if key, ok := builtinSectionKeys[name]; ok {
+		return key
+	}
+	return name
+}
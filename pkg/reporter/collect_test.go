@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSectionKeysStable(t *testing.T) {
+	stub := func(name string) Collector {
+		return NewCollector(name, name, func(ctx context.Context) (interface{}, error) { return name, nil })
+	}
+
+	tests := []struct {
+		name       string
+		collectors []string
+		want       map[string]string // ключ -> имя сборщика
+	}{
+		{"all builtins", []string{SectionHost, SectionCPU, SectionDocker, SectionSecurity},
+			map[string]string{"1": SectionHost, "2": SectionCPU, "7": SectionDocker, "8": SectionSecurity}},
+		{"docker disabled", []string{SectionHost, SectionCPU, SectionSecurity},
+			map[string]string{"1": SectionHost, "2": SectionCPU, "8": SectionSecurity}},
+		{"reordered with custom", []string{"nginx", SectionSecurity, SectionHost},
+			map[string]string{"nginx": "nginx", "8": SectionSecurity, "1": SectionHost}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var collectors []Collector
+			for _, name := range tt.collectors {
+				collectors = append(collectors, stub(name))
+			}
+			sections, err := collectSections(context.Background(), collectors, collectOptions{workers: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sections) != len(tt.want) {
+				t.Fatalf("got %d sections, want %d", len(sections), len(tt.want))
+			}
+			for key, name := range tt.want {
+				if sections[key].Name != name {
+					t.Errorf("section %q = %q, want %q", key, sections[key].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterRejectsNumericName(t *testing.T) {
+	r := New(nil)
+	err := r.Register(NewCollector("7", "SEVEN", func(ctx context.Context) (interface{}, error) { return nil, nil }))
+	if err == nil {
+		t.Fatal("numeric collector name accepted")
+	}
+}
+
+func TestCollectWarningsUseLogOutput(t *testing.T) {
+	var log bytes.Buffer
+	LogOutput = &log
+	t.Cleanup(func() { LogOutput = os.Stdout })
+
+	collectors := []Collector{
+		NewCollector("broken", "BROKEN", func(ctx context.Context) (interface{}, error) { return nil, errors.New("boom") }),
+		NewCollector("absent", "ABSENT", func(ctx context.Context) (interface{}, error) {
+			return nil, fmt.Errorf("no socket: %w", ErrUnavailable)
+		}),
+	}
+	sections, err := collectSections(context.Background(), collectors, collectOptions{workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sections["broken"].Status != SectionStatusError || sections["absent"].Status != SectionStatusUnavailable {
+		t.Errorf("statuses = %q, %q", sections["broken"].Status, sections["absent"].Status)
+	}
+	if got := log.String(); got != "Warning: failed to get BROKEN: boom\n" {
+		t.Errorf("log output = %q, want only the BROKEN warning", got)
+	}
+}
+
+// discardLog отключает сообщения пакета на время теста
+func discardLog(t *testing.T) {
+	t.Helper()
+	LogOutput = io.Discard
+	t.Cleanup(func() { LogOutput = os.Stdout })
+}
+
+// hangingCollector ждет отмены ctx и возвращает его ошибку
+func hangingCollector(name string) Collector {
+	return NewCollector(name, strings.ToUpper(name), func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+}
+
+// stuckCollector не соблюдает ctx и освобождается только в конце теста
+func stuckCollector(t *testing.T, name string) Collector {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	return NewCollector(name, strings.ToUpper(name), func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "late", nil
+	})
+}
+
+func TestCollectSectionStatuses(t *testing.T) {
+	discardLog(t)
+
+	tests := []struct {
+		name      string
+		collector Collector
+		status    string
+		errText   string
+		data      bool
+	}{
+		{"collected", NewCollector("ok", "OK", func(ctx context.Context) (interface{}, error) { return "up", nil }), "", "", true},
+		{"failed", NewCollector("broken", "BROKEN", func(ctx context.Context) (interface{}, error) {
+			return "partial", errors.New("permission denied")
+		}), SectionStatusError, "permission denied", false},
+		{"unavailable", NewCollector("absent", "ABSENT", func(ctx context.Context) (interface{}, error) {
+			return nil, fmt.Errorf("no docker socket: %w", ErrUnavailable)
+		}), SectionStatusUnavailable, "no docker socket: source unavailable", false},
+		{"blocks on ctx", hangingCollector("hung"), SectionStatusTimeout, "", false},
+		{"ignores ctx", stuckCollector(t, "stuck"), SectionStatusTimeout, "collector timed out after 20ms", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			sections, err := collectSections(context.Background(), []Collector{tt.collector}, collectOptions{workers: 1, collectorTimeout: 20 * time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("collection took %s", elapsed)
+			}
+			section := sections[tt.collector.Name()]
+			if section.Status != tt.status {
+				t.Errorf("status = %q, want %q", section.Status, tt.status)
+			}
+			if tt.errText != "" && section.Error != tt.errText {
+				t.Errorf("error = %q, want %q", section.Error, tt.errText)
+			}
+			if tt.status != "" && section.Error == "" {
+				t.Error("failed section has no error text")
+			}
+			if (section.Data != nil) != tt.data {
+				t.Errorf("data = %v, want present %v", section.Data, tt.data)
+			}
+		})
+	}
+}
+
+func TestCollectReportDeadline(t *testing.T) {
+	discardLog(t)
+
+	fast := func(name string) Collector {
+		return NewCollector(name, strings.ToUpper(name), func(ctx context.Context) (interface{}, error) { return name, nil })
+	}
+	// Один обработчик: после зависшего сборщика очередь до "queued" не доходит
+	collectors := []Collector{fast("first"), stuckCollector(t, "stuck"), fast("queued")}
+	opts := collectOptions{workers: 1, reportTimeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	sections, err := collectSections(context.Background(), collectors, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("report deadline did not stop collection, took %s", elapsed)
+	}
+
+	if s := sections["first"]; s.Status != "" || s.Data != "first" {
+		t.Errorf("first = %+v, want collected", s)
+	}
+	for _, name := range []string{"stuck", "queued"} {
+		s := sections[name]
+		if s.Status != SectionStatusTimeout || s.Error != "report deadline of 50ms exceeded" {
+			t.Errorf("%s = status %q, error %q; want timeout by report deadline", name, s.Status, s.Error)
+		}
+	}
+}
+
+func TestCollectCanceled(t *testing.T) {
+	discardLog(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := collectSections(ctx, []Collector{hangingCollector("hung")}, collectOptions{workers: 1, reportTimeout: time.Second})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
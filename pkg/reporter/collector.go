@@ -0,0 +1,180 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Имена встроенных секций отчета
+const (
+	SectionHost      = "host"
+	SectionCPU       = "cpu"
+	SectionMemory    = "memory"
+	SectionDisk      = "disk"
+	SectionNetwork   = "network"
+	SectionProcesses = "processes"
+	SectionDocker    = "docker"
+	SectionSecurity  = "security"
+)
+
+// Collector собирает данные одной секции отчета
+type Collector interface {
+	// Name возвращает уникальное имя секции (например, "cpu")
+	Name() string
+	// Title возвращает заголовок секции для отчета
+	Title() string
+	// Collect собирает данные секции; реализация должна завершаться
+	// при отмене ctx
+	Collect(ctx context.Context) (interface{}, error)
+}
+
+// funcCollector адаптирует функцию к интерфейсу Collector
+type funcCollector struct {
+	name  string
+	title string
+	fn    func(ctx context.Context) (interface{}, error)
+}
+
+func (c *funcCollector) Name() string  { return c.name }
+func (c *funcCollector) Title() string { return c.title }
+func (c *funcCollector) Collect(ctx context.Context) (interface{}, error) {
+	return c.fn(ctx)
+}
+
+// NewCollector создает Collector из функции сбора данных
+func NewCollector(name, title string, fn func(ctx context.Context) (interface{}, error)) Collector {
+	return &funcCollector{name: name, title: title, fn: fn}
+}
+
+// typedCollector оборачивает типизированную функцию сбора данных
+func typedCollector[T any](name, title string, fn func(ctx context.Context) (T, error)) Collector {
+	return NewCollector(name, title, func(ctx context.Context) (interface{}, error) {
+		return fn(ctx)
+	})
+}
+
+// builtinCollectors возвращает встроенные секции в порядке по умолчанию
+func builtinCollectors(config *Config) []Collector {
+	return []Collector{
+		typedCollector(SectionHost, "HOST INFORMATION", getHostInformation),
+		typedCollector(SectionCPU, "CPU INFORMATION", getCPUInformation),
+		typedCollector(SectionMemory, "MEMORY INFORMATION", getMemoryInformation),
+		typedCollector(SectionDisk, "DISK INFORMATION", getDiskInformation),
+		typedCollector(SectionNetwork, "NETWORK INFORMATION", getNetworkInformation),
+		typedCollector(SectionProcesses, "TOP PROCESSES BY MEMORY", getTopProcessesByMemory),
+		typedCollector(SectionDocker, "DOCKER CONTAINERS", newDockerClient(config.DockerSocket).listContainers),
+		typedCollector(SectionSecurity, "SECURITY STATUS", newSecurityCollector(config.Security).collect),
+	}
+}
+
+// Register добавляет секцию в конец списка сборщиков
+func (r *Reporter) Register(c Collector) error {
+	if c == nil || c.Name() == "" {
+		return fmt.Errorf("collector must have a name")
+	}
+	// Числовые ключи зарезервированы за встроенными секциями (см. sectionKey)
+	if _, err := strconv.Atoi(c.Name()); err == nil {
+		return fmt.Errorf("collector name %q must not be a number", c.Name())
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.indexOf(c.Name()) >= 0 {
+		return fmt.Errorf("collector %q already registered", c.Name())
+	}
+	r.collectors = append(r.collectors, c)
+	return nil
+}
+
+// Unregister удаляет секцию из списка сборщиков
+func (r *Reporter) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexOf(name)
+	if i < 0 {
+		return false
+	}
+	r.collectors = append(r.collectors[:i], r.collectors[i+1:]...)
+	delete(r.disabled, name)
+	delete(r.builtins, name)
+	return true
+}
+
+// Disable отключает секции без удаления их из списка
+func (r *Reporter) Disable(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		r.disabled[name] = true
+	}
+}
+
+// Enable включает ранее отключенные секции
+func (r *Reporter) Enable(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		delete(r.disabled, name)
+	}
+}
+
+// Reorder задает порядок сбора секций; не перечисленные секции идут следом
+// в прежнем порядке. Ключи секций в отчете от порядка не зависят.
+func (r *Reporter) Reorder(names ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ordered := make([]Collector, 0, len(r.collectors))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		i := r.indexOf(name)
+		if i < 0 {
+			return fmt.Errorf("collector %q is not registered", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("collector %q listed twice", name)
+		}
+		seen[name] = true
+		ordered = append(ordered, r.collectors[i])
+	}
+	for _, c := range r.collectors {
+		if !seen[c.Name()] {
+			ordered = append(ordered, c)
+		}
+	}
+	r.collectors = ordered
+	return nil
+}
+
+// Collectors возвращает включенные сборщики в порядке сбора
+func (r *Reporter) Collectors() []Collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []Collector
+	for _, c := range r.collectors {
+		if !r.disabled[c.Name()] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// resetBuiltins пересоздает встроенные сборщики под новую конфигурацию,
+// сохраняя их позиции; пользовательские сборщики не затрагиваются
+func (r *Reporter) resetBuiltins(config *Config) {
+	for _, fresh := range builtinCollectors(config) {
+		name := fresh.Name()
+		if i := r.indexOf(name); i >= 0 && r.collectors[i] == r.builtins[name] {
+			r.collectors[i] = fresh
+			r.builtins[name] = fresh
+		}
+	}
+}
+
+func (r *Reporter) indexOf(name string) int {
+	for i, c := range r.collectors {
+		if c.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
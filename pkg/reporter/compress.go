@@ -0,0 +1,56 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Алгоритмы сжатия тела запроса (Config.Compression)
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// DefaultCompressionThreshold размер тела, меньше которого сжатие не применяется
+const DefaultCompressionThreshold = 4 * 1024
+
+// compressBody сжимает тело запроса выбранным алгоритмом и возвращает
+// значение заголовка Content-Encoding. Тела меньше threshold и тела,
+// которые после сжатия не стали меньше, отправляются как есть.
+func compressBody(algorithm string, threshold int, data []byte) ([]byte, string, error) {
+	if algorithm == "" || algorithm == CompressionNone || len(data) < threshold {
+		return data, "", nil
+	}
+
+	var compressed []byte
+	switch algorithm {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, "", fmt.Errorf("failed to gzip report: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, "", fmt.Errorf("failed to gzip report: %v", err)
+		}
+		compressed = buf.Bytes()
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create zstd encoder: %v", err)
+		}
+		compressed = enc.EncodeAll(data, nil)
+		enc.Close()
+	default:
+		return nil, "", fmt.Errorf("unsupported compression %q", algorithm)
+	}
+
+	if len(compressed) >= len(data) {
+		return data, "", nil
+	}
+	return compressed, algorithm, nil
+}
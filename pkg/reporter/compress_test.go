@@ -0,0 +1,97 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// decompress распаковывает тело по значению Content-Encoding
+func decompress(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+	switch encoding {
+	case CompressionGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	case CompressionZstd:
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dec.Close()
+		data, err := dec.DecodeAll(body, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	t.Fatalf("unexpected encoding %q", encoding)
+	return nil
+}
+
+func TestCompressBody(t *testing.T) {
+	text := []byte(strings.Repeat(`{"name":"nginx: worker","memory":268435456},`, 200))
+	random := make([]byte, 8*1024)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		threshold int
+		data      []byte
+		encoding  string // пусто - тело отправляется как есть
+	}{
+		{"gzip", CompressionGzip, 1024, text, CompressionGzip},
+		{"zstd", CompressionZstd, 1024, text, CompressionZstd},
+		{"below threshold", CompressionGzip, len(text) + 1, text, ""},
+		{"exactly threshold", CompressionZstd, len(text), text, CompressionZstd},
+		{"disabled", CompressionNone, 1, text, ""},
+		{"empty algorithm", "", 1, text, ""},
+		{"gzip incompressible", CompressionGzip, 1, random, ""},
+		{"zstd incompressible", CompressionZstd, 1, random, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, encoding, err := compressBody(tt.algorithm, tt.threshold, tt.data)
+			if err != nil {
+				t.Fatalf("compressBody: %v", err)
+			}
+			if encoding != tt.encoding {
+				t.Fatalf("Content-Encoding = %q, want %q", encoding, tt.encoding)
+			}
+			if encoding == "" {
+				if !bytes.Equal(body, tt.data) {
+					t.Error("uncompressed body differs from input")
+				}
+				return
+			}
+			if len(body) >= len(tt.data) {
+				t.Errorf("compressed %d bytes into %d", len(tt.data), len(body))
+			}
+			if !bytes.Equal(decompress(t, encoding, body), tt.data) {
+				t.Error("round trip does not restore input")
+			}
+		})
+	}
+}
+
+func TestCompressBodyUnsupported(t *testing.T) {
+	_, _, err := compressBody("brotli", 1, []byte("data"))
+	if err == nil || !strings.Contains(err.Error(), `unsupported compression "brotli"`) {
+		t.Errorf("error = %v, want unsupported compression", err)
+	}
+}
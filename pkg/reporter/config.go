@@ -0,0 +1,452 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigFile переменная окружения с путем к файлу конфигурации
+const EnvConfigFile = "REPORTER_CONFIG"
+
+// envPrefix префикс переменных окружения, переопределяющих ключи
+// конфигурации: tls.ca_file задается через REPORTER_TLS_CA_FILE
+const envPrefix = "REPORTER_"
+
+// redacted заменяет секреты в выводе конфигурации
+const redacted = "********"
+
+// configFileNames имена файла конфигурации в порядке поиска в каталоге
+var configFileNames = []string{"config.yaml", "config.yml", "config.json", "config.toml"}
+
+// ConfigSearchPaths возвращает пути, в которых LoadConfig ищет файл
+// конфигурации, по убыванию приоритета: ./reporter.*, каталог
+// пользовательских настроек (XDG_CONFIG_HOME/reporter) и /etc/reporter
+func ConfigSearchPaths() []string {
+	var paths []string
+	for _, name := range configFileNames {
+		paths = append(paths, "reporter"+strings.TrimPrefix(name, "config"))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		for _, name := range configFileNames {
+			paths = append(paths, filepath.Join(dir, "reporter", name))
+		}
+	}
+	for _, name := range configFileNames {
+		paths = append(paths, filepath.Join("/etc/reporter", name))
+	}
+	return paths
+}
+
+// FindConfigFile возвращает файл конфигурации из REPORTER_CONFIG или
+// первый существующий из ConfigSearchPaths; пустую строку, если его нет
+func FindConfigFile() string {
+	if path := os.Getenv(EnvConfigFile); path != "" {
+		return path
+	}
+	for _, path := range ConfigSearchPaths() {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadConfig строит конфигурацию по слоям: значения по умолчанию, файл,
+// переменные окружения REPORTER_*, затем overrides вида "ключ=значение"
+// (флаги командной строки). Пустой path означает поиск через
+// FindConfigFile. Результат проверяется Validate.
+func LoadConfig(path string, overrides []string) (*Config, error) {
+	config := DefaultConfig()
+
+	if path == "" {
+		path = FindConfigFile()
+	}
+	if path != "" {
+		if err := config.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid override %q, expected key=value", override)
+		}
+		if err := config.Set(strings.TrimSpace(key), value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// loadFile читает файл конфигурации; формат определяется по расширению,
+// неизвестные ключи считаются ошибкой
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		meta, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("failed to parse config %s: %v", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("failed to parse config %s: unknown key %q", path, undecoded[0].String())
+		}
+		return nil
+	case ".json":
+		// JSON перекодируется в YAML: в YAML нельзя отступать табуляцией
+		var tree interface{}
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return fmt.Errorf("failed to parse config %s: %v", path, err)
+		}
+		if data, err = yaml.Marshal(tree); err != nil {
+			return fmt.Errorf("failed to parse config %s: %v", path, err)
+		}
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+	return nil
+}
+
+// applyEnv применяет переменные окружения REPORTER_<КЛЮЧ>
+func (c *Config) applyEnv() error {
+	for _, key := range ConfigKeys() {
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if value, ok := os.LookupEnv(name); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ConfigKeys возвращает все ключи конфигурации через точку, например
+// "tls.ca_file"; их принимают Set, переменные окружения и -set
+func ConfigKeys() []string {
+	var keys []string
+	walkConfigFields(reflect.TypeOf(Config{}), "", func(key string, _ []int) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// Set задает значение по ключу конфигурации из строки. Длительности
+// задаются в формате time.ParseDuration, списки - через запятую,
+// словари - парами "k=v" через запятую.
+func (c *Config) Set(key, value string) error {
+	var index []int
+	walkConfigFields(reflect.TypeOf(*c), "", func(k string, i []int) {
+		if k == key {
+			index = i
+		}
+	})
+	if index == nil {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+
+	field := reflect.ValueOf(c).Elem().FieldByIndex(index)
+	if err := setFieldString(field, value); err != nil {
+		return fmt.Errorf("%s: %v", key, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// walkConfigFields обходит поля с тегом yaml, заходя во вложенные структуры
+func walkConfigFields(t reflect.Type, prefix string, fn func(key string, index []int)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		if field.Type.Kind() == reflect.Struct {
+			walkConfigFields(field.Type, key+".", func(k string, index []int) {
+				fn(k, append([]int{i}, index...))
+			})
+			continue
+		}
+		fn(key, []int{i})
+	}
+}
+
+func setFieldString(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Map:
+		items := make(map[string]string)
+		for _, item := range strings.Split(value, ",") {
+			k, v, ok := strings.Cut(item, "=")
+			if !ok {
+				if strings.TrimSpace(item) == "" {
+					continue
+				}
+				return fmt.Errorf("expected key=value pairs, got %q", item)
+			}
+			items[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// ConfigError ошибка проверки конфигурации со списком всех проблем
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid config:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Validate проверяет конфигурацию и сообщает обо всех найденных
+// проблемах сразу, с ключами в том виде, в каком они задаются в файле
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if u, err := url.Parse(c.APIBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("api_base_url: %q is not an absolute http(s) URL", c.APIBaseURL)
+	}
+	if c.ReportEndpoint != "" && !strings.HasPrefix(c.ReportEndpoint, "/") {
+		add("report_endpoint: %q must start with /", c.ReportEndpoint)
+	}
+	if c.Timeout <= 0 {
+		add("timeout: must be positive, got %s", c.Timeout)
+	}
+	if c.AuthToken != "" && c.AuthTokenFile != "" {
+		add("auth_token and auth_token_file are mutually exclusive")
+	}
+	if c.HMACSecret != "" && c.HMACSecretFile != "" {
+		add("hmac_secret and hmac_secret_file are mutually exclusive")
+	}
+
+	if _, ok := tlsVersions[c.TLS.MinVersion]; c.TLS.MinVersion != "" && !ok {
+		add("tls.min_version: unsupported version %q, use 1.2 or 1.3", c.TLS.MinVersion)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		add("tls.cert_file and tls.key_file must be set together")
+	}
+	if c.TLS.Required && strings.HasPrefix(c.APIBaseURL, "http://") {
+		add("tls.required: api_base_url %s is not https", c.APIBaseURL)
+	}
+
+	if !validCompression(c.Compression) {
+		add("compression: unsupported algorithm %q, use none, gzip or zstd", c.Compression)
+	}
+	if c.CompressionThreshold < 0 {
+		add("compression_threshold: must not be negative")
+	}
+
+	if c.Workers < 0 {
+		add("workers: must not be negative")
+	}
+	if c.CollectorTimeout < 0 {
+		add("collector_timeout: must not be negative")
+	}
+	if c.ReportTimeout < 0 {
+		add("report_timeout: must not be negative")
+	}
+
+	if c.Interval <= 0 {
+		add("interval: must be positive, got %s", c.Interval)
+	}
+	if c.Jitter < 0 {
+		add("jitter: must not be negative")
+	}
+	if c.MetricsListen != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsListen); err != nil {
+			add("metrics_listen: %v", err)
+		}
+	}
+	if c.SpoolMaxBytes < 0 {
+		add("spool_max_bytes: must not be negative")
+	}
+	if c.SpoolMaxAge < 0 {
+		add("spool_max_age: must not be negative")
+	}
+
+	if c.Retry.MaxAttempts < 1 {
+		add("retry.max_attempts: must be at least 1")
+	}
+	if c.Retry.Multiplier != 0 && c.Retry.Multiplier < 1 {
+		add("retry.multiplier: must be at least 1")
+	}
+	if c.Retry.InitialBackoff < 0 || c.Retry.MaxBackoff < 0 || c.Retry.BreakerCooldown < 0 {
+		add("retry: backoff and cooldown durations must not be negative")
+	}
+	if c.Retry.BreakerThreshold < 0 {
+		add("retry.breaker_threshold: must not be negative")
+	}
+
+	if c.OTLP.Enabled {
+		if u, err := url.Parse(c.OTLP.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("otlp.endpoint: %q is not an absolute http(s) URL", c.OTLP.Endpoint)
+		}
+		if !validCompression(c.OTLP.Compression) {
+			add("otlp.compression: unsupported algorithm %q, use none, gzip or zstd", c.OTLP.Compression)
+		}
+		if _, ok := tlsVersions[c.OTLP.TLS.MinVersion]; c.OTLP.TLS.MinVersion != "" && !ok {
+			add("otlp.tls.min_version: unsupported version %q, use 1.2 or 1.3", c.OTLP.TLS.MinVersion)
+		}
+		if (c.OTLP.TLS.CertFile == "") != (c.OTLP.TLS.KeyFile == "") {
+			add("otlp.tls.cert_file and otlp.tls.key_file must be set together")
+		}
+	}
+
+	if c.Influx.Enabled && !validMetricsURL(c.Influx.URL, "tcp", "udp", "http", "https") {
+		add("influx.url: %q must be a tcp://, udp://, http:// or https:// URL", c.Influx.URL)
+	}
+	if c.Graphite.Enabled && !validMetricsURL(c.Graphite.URL, "tcp", "udp") {
+		add("graphite.url: %q must be a tcp:// or udp:// URL", c.Graphite.URL)
+	}
+
+	if c.FullResyncEvery < 0 {
+		add("full_resync_every: must not be negative")
+	}
+	if c.FullResyncInterval < 0 {
+		add("full_resync_interval: must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+func validMetricsURL(target string, schemes ...string) bool {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+func validCompression(algorithm string) bool {
+	switch algorithm {
+	case "", CompressionNone, CompressionGzip, CompressionZstd:
+		return true
+	}
+	return false
+}
+
+// Redacted возвращает копию конфигурации со скрытыми секретами: токены,
+// значения заголовков OTLP и учетные данные в адресах
+func (c *Config) Redacted() *Config {
+	out := *c
+	if out.AuthToken != "" {
+		out.AuthToken = redacted
+	}
+	if out.HMACSecret != "" {
+		out.HMACSecret = redacted
+	}
+	if out.Influx.Token != "" {
+		out.Influx.Token = redacted
+	}
+	if len(c.OTLP.Headers) > 0 {
+		out.OTLP.Headers = make(map[string]string, len(c.OTLP.Headers))
+		for key := range c.OTLP.Headers {
+			out.OTLP.Headers[key] = redacted
+		}
+	}
+	out.APIBaseURL = redactURL(out.APIBaseURL)
+	out.OTLP.Endpoint = redactURL(out.OTLP.Endpoint)
+	out.Influx.URL = redactURL(out.Influx.URL)
+	out.Graphite.URL = redactURL(out.Graphite.URL)
+	return &out
+}
+
+// redactURL убирает из адреса имя пользователя и пароль
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = nil
+	return u.String()
+}
+
+// ToYAML кодирует конфигурацию в YAML со скрытыми секретами
+func (c *Config) ToYAML() ([]byte, error) {
+	return yaml.Marshal(c.Redacted())
+}
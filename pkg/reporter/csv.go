@@ -0,0 +1,112 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Файлы WriteCSV по табличным секциям
+const (
+	CSVDisks      = "disks.csv"
+	CSVInterfaces = "interfaces.csv"
+	CSVProcesses  = "processes.csv"
+	CSVContainers = "containers.csv"
+)
+
+// WriteCSV сохраняет табличные секции отчета (DiskInfo, InterfaceInfo,
+// ProcessInfo, DockerContainer) в каталог dir, по файлу на секцию.
+// Первая колонка - host_id, остальные совпадают с полями JSON; объемы
+// остаются в единицах отчета. Файлы создаются всегда, с заголовком,
+// даже если секция пуста или не собрана.
+func WriteCSV(report *SystemReport, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	tables := []struct {
+		file   string
+		header []string
+		rows   func(h hostData) [][]string
+	}{
+		{CSVDisks, []string{"device", "mountpoint", "filesystem", "total_gb", "used_gb", "used_percent", "free_gb"},
+			func(h hostData) [][]string {
+				var rows [][]string
+				for _, d := range h.Disks {
+					rows = append(rows, []string{d.Device, d.Mountpoint, d.Filesystem,
+						csvFloat(d.TotalGB), csvFloat(d.UsedGB), csvFloat(d.UsedPercent), csvFloat(d.FreeGB)})
+				}
+				return rows
+			}},
+		{CSVInterfaces, []string{"name", "mac", "ips", "sent_gb", "received_gb"},
+			func(h hostData) [][]string {
+				if h.Network == nil {
+					return nil
+				}
+				var rows [][]string
+				for _, iface := range h.Network.Interfaces {
+					rows = append(rows, []string{iface.Name, iface.MAC, strings.Join(iface.IPs, " "),
+						csvFloat(iface.Statistics.SentGB), csvFloat(iface.Statistics.ReceivedGB)})
+				}
+				return rows
+			}},
+		{CSVProcesses, []string{"pid", "name", "memory_mb", "cpu_percent"},
+			func(h hostData) [][]string {
+				var rows [][]string
+				for _, p := range h.Processes {
+					rows = append(rows, []string{strconv.Itoa(int(p.PID)), p.Name, csvFloat(p.MemoryMB), csvFloat(p.CPUPercent)})
+				}
+				return rows
+			}},
+		{CSVContainers, []string{"container_id", "name", "image", "status", "uptime"},
+			func(h hostData) [][]string {
+				var rows [][]string
+				for _, c := range h.Docker {
+					rows = append(rows, []string{c.ContainerID, c.Name, c.Image, c.Status, c.Uptime})
+				}
+				return rows
+			}},
+	}
+
+	hosts := make([]hostData, 0, len(report.Reports))
+	for _, r := range report.Reports {
+		hosts = append(hosts, newHostData(r))
+	}
+
+	for _, table := range tables {
+		records := [][]string{append([]string{"host_id"}, table.header...)}
+		for _, h := range hosts {
+			for _, row := range table.rows(h) {
+				records = append(records, append([]string{h.HostID}, row...))
+			}
+		}
+		if err := writeCSVFile(filepath.Join(dir, table.file), records); err != nil {
+			return err
+		}
+	}
+	logf("Report tables saved to %s\n", dir)
+	return nil
+}
+
+func writeCSVFile(filename string, records [][]string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(records); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %v", filename, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filename, err)
+	}
+	return nil
+}
+
+func csvFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
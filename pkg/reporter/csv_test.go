@@ -0,0 +1,63 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "report-csv")
+	report := sampleReport()
+	delete(report.Reports[0].Sections, "5")
+	if err := WriteCSV(report, dir); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want [][]string
+	}{
+		{CSVDisks, [][]string{
+			{"host_id", "device", "mountpoint", "filesystem", "total_gb", "used_gb", "used_percent", "free_gb"},
+			{"web1", "/dev/sda1", "/", "ext4", "100", "40", "40", "60"},
+			{"web1", "/dev/sdb1", "/var/lib/data store", "xfs", "500", "450", "90", "50"},
+		}},
+		{CSVInterfaces, [][]string{{"host_id", "name", "mac", "ips", "sent_gb", "received_gb"}}},
+		{CSVProcesses, [][]string{
+			{"host_id", "pid", "name", "memory_mb", "cpu_percent"},
+			{"web1", "1", "systemd", "12", "0.1"},
+			{"web1", "812", "nginx: worker", "256", "4.5"},
+		}},
+		{CSVContainers, [][]string{{"host_id", "container_id", "name", "image", "status", "uptime"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			records, err := csv.NewReader(f).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(records, tt.want) {
+				t.Errorf("records = %q, want %q", records, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVExporterRejectsStdout(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := CSVExporter().Export(sampleReport(), "-"); err == nil {
+		t.Fatal("csv export to - succeeded, want error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "-")); !os.IsNotExist(err) {
+		t.Errorf("directory %q created: %v", "-", err)
+	}
+}
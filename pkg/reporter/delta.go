@@ -0,0 +1,205 @@
+package reporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// HeaderResync заголовок ответа, которым сервер просит следующий отчет целиком
+const HeaderResync = "X-Reporter-Resync"
+
+// Периодичность полной пересылки при дельта-отправке по умолчанию
+const (
+	DefaultFullResyncEvery    = 12
+	DefaultFullResyncInterval = 24 * time.Hour
+)
+
+// DefaultStateFile возвращает путь к файлу состояния дельта-отправки
+func DefaultStateFile() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "system-reporter", "state.json")
+}
+
+// deltaState хеши последнего отчета, успешно доставленного на сервер
+type deltaState struct {
+	ReportHash     string            `json:"report_hash"`
+	Sections       map[string]string `json:"sections"`
+	SendsSinceFull int               `json:"sends_since_full"`
+	LastFull       time.Time         `json:"last_full"`
+	ForceFull      bool              `json:"force_full,omitempty"`
+}
+
+// deltaPlan решение об отправке одного отчета: дельта или полный отчет,
+// и состояние, которое нужно сохранить после успешной отправки.
+// Методы допускают nil-получатель: дельта-отправка выключена.
+type deltaPlan struct {
+	path  string
+	delta *APIReportRequest // nil - нужен полный отчет
+	prev  deltaState
+	next  deltaState
+}
+
+// planDelta сравнивает хеши секций отчета с сохраненными и готовит
+// дельта-запрос, содержащий только изменившиеся секции. Полный отчет
+// отправляется, если состояния нет, сервер просил пересылку или подошел
+// срок периодической полной пересылки. Секции сравниваются целиком, кроме
+// полей Config.DeltaExcludeFields: HashExcludeFields сюда не применяются,
+// иначе изменения загрузки CPU или памяти никогда не доходили бы до сервера.
+func planDelta(config *Config, report *SystemReport, full *APIReportRequest) (*deltaPlan, error) {
+	if len(report.Reports) != 1 {
+		return nil, fmt.Errorf("delta reporting supports single-host reports only")
+	}
+
+	reportHash, err := CalculateReportHash(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash report: %v", err)
+	}
+	sectionHashes, err := SectionHashes(&report.Reports[0], config.DeltaExcludeFields)
+	if err != nil {
+		return nil, err
+	}
+	full.ReportHash = reportHash
+
+	path := config.StateFile
+	if path == "" {
+		path = DefaultStateFile()
+	}
+	plan := &deltaPlan{
+		path: path,
+		next: deltaState{ReportHash: reportHash, Sections: sectionHashes},
+	}
+
+	prev, err := loadDeltaState(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logf("Warning: ignoring unreadable delta state: %v\n", err)
+		}
+		return plan, nil
+	}
+	plan.prev = prev
+
+	every := config.FullResyncEvery
+	if every <= 0 {
+		every = DefaultFullResyncEvery
+	}
+	interval := config.FullResyncInterval
+	if interval <= 0 {
+		interval = DefaultFullResyncInterval
+	}
+	if prev.ForceFull || prev.ReportHash == "" || prev.SendsSinceFull+1 >= every || time.Since(prev.LastFull) >= interval {
+		return plan, nil
+	}
+
+	// Оставляем в копии отчета только изменившиеся секции
+	partial := *report
+	partial.Reports = []Report{report.Reports[0]}
+	partial.Reports[0].Sections = make(map[string]Section)
+	for key, section := range report.Reports[0].Sections {
+		if prev.Sections[key] != sectionHashes[key] {
+			partial.Reports[0].Sections[key] = section
+		}
+	}
+	var removed []string
+	for key := range prev.Sections {
+		if _, ok := sectionHashes[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+
+	reportData, err := ConvertToMap(&partial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert delta report: %v", err)
+	}
+	plan.delta = &APIReportRequest{
+		Agent:           full.Agent,
+		Report:          reportData,
+		Delta:           true,
+		BaseHash:        prev.ReportHash,
+		ReportHash:      reportHash,
+		RemovedSections: removed,
+	}
+	logf("Delta report: %d of %d sections changed\n", len(partial.Reports[0].Sections), len(sectionHashes))
+	return plan, nil
+}
+
+// requestFor возвращает запрос для отправки: дельту, если она подготовлена
+func (p *deltaPlan) requestFor(full *APIReportRequest) *APIReportRequest {
+	if p == nil || p.delta == nil {
+		return full
+	}
+	return p.delta
+}
+
+// forceFull отменяет дельту для текущей отправки
+func (p *deltaPlan) forceFull() {
+	if p != nil {
+		p.delta = nil
+	}
+}
+
+// commit сохраняет состояние после успешной отправки
+func (p *deltaPlan) commit(sentFull, resync bool) {
+	if p == nil {
+		return
+	}
+	next := p.next
+	if sentFull {
+		next.LastFull = time.Now()
+	} else {
+		next.LastFull = p.prev.LastFull
+		next.SendsSinceFull = p.prev.SendsSinceFull + 1
+	}
+	next.ForceFull = resync
+	if resync {
+		logf("Server requested full resync on the next report\n")
+	}
+	if err := saveDeltaState(p.path, next); err != nil {
+		logf("Warning: failed to save delta state: %v\n", err)
+	}
+}
+
+// isResyncRequest сообщает, что сервер отверг дельту: не знает базовый
+// отчет (409 Conflict) или базовый хеш не совпал (412 Precondition Failed)
+func isResyncRequest(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.StatusCode == http.StatusConflict || apiErr.StatusCode == http.StatusPreconditionFailed)
+}
+
+func loadDeltaState(path string) (deltaState, error) {
+	var state deltaState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return state, nil
+}
+
+// saveDeltaState атомарно записывает состояние через временный файл
+func saveDeltaState(path string, state deltaState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
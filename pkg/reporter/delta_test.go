@@ -0,0 +1,259 @@
+package reporter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// deltaSections возвращает ключи секций дельта-запроса
+func deltaSections(t *testing.T, request *APIReportRequest) []string {
+	t.Helper()
+	reports, _ := request.Report["reports"].([]interface{})
+	if len(reports) != 1 {
+		t.Fatalf("delta has %d reports, want 1", len(reports))
+	}
+	sections, _ := reports[0].(map[string]interface{})["sections"].(map[string]interface{})
+	keys := make([]string, 0, len(sections))
+	for key := range sections {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// planAndCommit готовит отправку отчета и сохраняет состояние, как после
+// успешной отправки выбранного запроса
+func planAndCommit(t *testing.T, config *Config, report *SystemReport, resync bool) *deltaPlan {
+	t.Helper()
+	plan, err := planDelta(config, report, &APIReportRequest{Agent: "test"})
+	if err != nil {
+		t.Fatalf("planDelta: %v", err)
+	}
+	plan.commit(plan.delta == nil, resync)
+	return plan
+}
+
+func TestDeltaStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "state.json")
+	state := deltaState{
+		ReportHash:     "abc",
+		Sections:       map[string]string{"1": "h1", "nginx": "h2"},
+		SendsSinceFull: 3,
+		LastFull:       time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+		ForceFull:      true,
+	}
+	if err := saveDeltaState(path, state); err != nil {
+		t.Fatalf("saveDeltaState: %v", err)
+	}
+	loaded, err := loadDeltaState(path)
+	if err != nil {
+		t.Fatalf("loadDeltaState: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, state) {
+		t.Errorf("loaded state = %+v, want %+v", loaded, state)
+	}
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("temporary state file left behind: %v", err)
+	}
+
+	if _, err := loadDeltaState(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing state error = %v, want ErrNotExist", err)
+	}
+	if err := os.WriteFile(path, []byte("{broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDeltaState(path); err == nil {
+		t.Error("corrupt state parsed without error")
+	}
+}
+
+func TestPlanDelta(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  func(*Config)
+		prepare func(t *testing.T, config *Config) // предыдущие отправки
+		change  func(report *SystemReport)
+		full    bool
+		changed []string
+		removed []string
+	}{
+		{
+			name: "no state sends full report",
+			full: true,
+		},
+		{
+			name:    "report metadata does not mark sections changed",
+			prepare: func(t *testing.T, config *Config) { planAndCommit(t, config, sampleReport(), false) },
+			change: func(report *SystemReport) {
+				report.Generated = report.Generated.Add(5 * time.Minute)
+				host := &report.Reports[0]
+				host.Timestamp = host.Timestamp.Add(5 * time.Minute)
+				host.ReportNumber++
+			},
+			changed: []string{},
+		},
+		{
+			name:    "live metrics mark sections changed",
+			prepare: func(t *testing.T, config *Config) { planAndCommit(t, config, sampleReport(), false) },
+			change: func(report *SystemReport) {
+				sections := report.Reports[0].Sections
+				sections["2"].Data.(*CPUInfo).UsagePercent = 87
+				sections["3"].Data.(*MemoryInfo).RAM.FreeGB = 1
+				sections["6"].Data.([]ProcessInfo)[1].CPUPercent = 50
+			},
+			changed: []string{"2", "3", "6"},
+		},
+		{
+			name:    "only changed sections are sent",
+			prepare: func(t *testing.T, config *Config) { planAndCommit(t, config, sampleReport(), false) },
+			change: func(report *SystemReport) {
+				report.Reports[0].Sections["4"].Data.([]DiskInfo)[1].TotalGB = 1000
+				report.Reports[0].Sections["2"].Data.(*CPUInfo).UsagePercent = 99
+			},
+			changed: []string{"2", "4"},
+		},
+		{
+			name:    "removed sections are listed",
+			prepare: func(t *testing.T, config *Config) { planAndCommit(t, config, sampleReport(), false) },
+			change: func(report *SystemReport) {
+				delete(report.Reports[0].Sections, "6")
+				report.Reports[0].Sections["nginx"] = Section{Name: "nginx", Title: "NGINX", Data: map[string]string{"status": "up"}}
+			},
+			changed: []string{"nginx"},
+			removed: []string{"6"},
+		},
+		{
+			name:    "delta exclusions",
+			config:  func(c *Config) { c.DeltaExcludeFields = []string{"reports.sections.cpu.data.usage_percent"} },
+			prepare: func(t *testing.T, config *Config) { planAndCommit(t, config, sampleReport(), false) },
+			change: func(report *SystemReport) {
+				report.Reports[0].Sections["2"].Data.(*CPUInfo).UsagePercent = 99
+				report.Reports[0].Sections["6"].Data.([]ProcessInfo)[1].CPUPercent = 50
+			},
+			changed: []string{"6"},
+		},
+		{
+			name:    "hash exclusions do not apply",
+			config:  func(c *Config) { c.HashExcludeFields = DefaultHashExcludeFields },
+			prepare: func(t *testing.T, config *Config) { planAndCommit(t, config, sampleReport(), false) },
+			change:  func(report *SystemReport) { report.Reports[0].Sections["1"].Data.(*HostInfo).Uptime.Hours++ },
+			changed: []string{"1"},
+		},
+		{
+			name:    "server requested resync",
+			prepare: func(t *testing.T, config *Config) { planAndCommit(t, config, sampleReport(), true) },
+			full:    true,
+		},
+		{
+			name:   "periodic full resync by count",
+			config: func(c *Config) { c.FullResyncEvery = 2 },
+			prepare: func(t *testing.T, config *Config) {
+				planAndCommit(t, config, sampleReport(), false)
+				planAndCommit(t, config, sampleReport(), false)
+			},
+			full: true,
+		},
+		{
+			name: "corrupt state sends full report",
+			prepare: func(t *testing.T, config *Config) {
+				if err := os.WriteFile(config.StateFile, []byte("not json"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			full: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.StateFile = filepath.Join(t.TempDir(), "state.json")
+			if tt.config != nil {
+				tt.config(config)
+			}
+			if tt.prepare != nil {
+				tt.prepare(t, config)
+			}
+
+			report := sampleReport()
+			if tt.change != nil {
+				tt.change(report)
+			}
+			plan, err := planDelta(config, report, &APIReportRequest{Agent: "test"})
+			if err != nil {
+				t.Fatalf("planDelta: %v", err)
+			}
+			if tt.full {
+				if plan.delta != nil {
+					t.Fatalf("got delta with sections %v, want full report", deltaSections(t, plan.delta))
+				}
+				return
+			}
+			if plan.delta == nil {
+				t.Fatal("got full report, want delta")
+			}
+			if got := deltaSections(t, plan.delta); !reflect.DeepEqual(got, tt.changed) {
+				t.Errorf("changed sections = %v, want %v", got, tt.changed)
+			}
+			if !reflect.DeepEqual(plan.delta.RemovedSections, tt.removed) {
+				t.Errorf("removed sections = %v, want %v", plan.delta.RemovedSections, tt.removed)
+			}
+			if plan.delta.BaseHash != plan.prev.ReportHash || plan.delta.BaseHash == "" {
+				t.Errorf("base hash = %q, want previous report hash %q", plan.delta.BaseHash, plan.prev.ReportHash)
+			}
+		})
+	}
+}
+
+func TestDeltaCommit(t *testing.T) {
+	config := DefaultConfig()
+	config.StateFile = filepath.Join(t.TempDir(), "state.json")
+
+	planAndCommit(t, config, sampleReport(), false)
+	first, err := loadDeltaState(config.StateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.SendsSinceFull != 0 || first.LastFull.IsZero() || len(first.Sections) != 6 {
+		t.Fatalf("state after full send = %+v", first)
+	}
+
+	planAndCommit(t, config, sampleReport(), false)
+	second, err := loadDeltaState(config.StateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.SendsSinceFull != 1 || !second.LastFull.Equal(first.LastFull) {
+		t.Errorf("state after delta send = %+v, want 1 send since full at %s", second, first.LastFull)
+	}
+
+	// Дельта-отправка выключена: nil-план ничего не сохраняет
+	var plan *deltaPlan
+	full := &APIReportRequest{Agent: "test"}
+	if plan.requestFor(full) != full {
+		t.Error("nil plan must send the full request")
+	}
+	plan.forceFull()
+	plan.commit(true, false)
+}
+
+func TestIsResyncRequest(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: 409}, true},
+		{&APIError{StatusCode: 412}, true},
+		{&APIError{StatusCode: 400}, false},
+		{errors.New("conflict"), false},
+	}
+	for _, tt := range tests {
+		if got := isResyncRequest(tt.err); got != tt.want {
+			t.Errorf("isResyncRequest(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
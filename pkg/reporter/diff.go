@@ -0,0 +1,151 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Виды различий между отчетами
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// ReportChange одно различие между двумя отчетами
+type ReportChange struct {
+	Type string      // ChangeAdded, ChangeRemoved или ChangeModified
+	Path string      // Путь к значению: reports[host].sections.cpu.data.cores
+	Old  interface{} // Значение в первом отчете
+	New  interface{} // Значение во втором отчете
+}
+
+// String форматирует различие в одну строку: "+", "-" или "~" и путь
+func (c ReportChange) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, leafString(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, leafString(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, leafString(c.Old), leafString(c.New))
+	}
+}
+
+// DiffReports сравнивает два отчета по значениям. Хосты сопоставляются
+// по host_id, секции - по имени сборщика, поэтому перестановка секций
+// различием не считается. Поля exclude (см. DefaultHashExcludeFields)
+// не сравниваются. Различия упорядочены по пути.
+func DiffReports(before, after *SystemReport, exclude []string) ([]ReportChange, error) {
+	oldLeaves, err := reportLeaves(before, exclude)
+	if err != nil {
+		return nil, err
+	}
+	newLeaves, err := reportLeaves(after, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ReportChange
+	for path, oldValue := range oldLeaves {
+		newValue, ok := newLeaves[path]
+		switch {
+		case !ok:
+			changes = append(changes, ReportChange{Type: ChangeRemoved, Path: path, Old: oldValue})
+		case leafString(oldValue) != leafString(newValue):
+			changes = append(changes, ReportChange{Type: ChangeModified, Path: path, Old: oldValue, New: newValue})
+		}
+	}
+	for path, newValue := range newLeaves {
+		if _, ok := oldLeaves[path]; !ok {
+			changes = append(changes, ReportChange{Type: ChangeAdded, Path: path, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// reportLeaves раскладывает отчет в пары путь - скалярное значение
+func reportLeaves(report *SystemReport, exclude []string) (map[string]interface{}, error) {
+	tree, err := canonicalTree(report)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range exclude {
+		removePath(tree, strings.Split(path, "."), "")
+	}
+
+	leaves := make(map[string]interface{})
+	root, _ := tree.(map[string]interface{})
+	for key, value := range root {
+		if key != "reports" {
+			flattenInto(leaves, key, value)
+			continue
+		}
+		hosts, _ := value.([]interface{})
+		for i, host := range hosts {
+			hostMap, _ := host.(map[string]interface{})
+			id, _ := hostMap["host_id"].(string)
+			if id == "" {
+				id = strconv.Itoa(i)
+			}
+			prefix := "reports[" + id + "]"
+			for field, fieldValue := range hostMap {
+				if field == "sections" {
+					fieldValue = sectionsByName(fieldValue)
+				}
+				flattenInto(leaves, prefix+"."+field, fieldValue)
+			}
+		}
+	}
+	return leaves, nil
+}
+
+// sectionsByName переключает ключи секций с номеров на имена сборщиков
+func sectionsByName(value interface{}) interface{} {
+	sections, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	named := make(map[string]interface{}, len(sections))
+	for key, section := range sections {
+		if s, ok := section.(map[string]interface{}); ok {
+			if name, _ := s["name"].(string); name != "" {
+				key = name
+			}
+		}
+		named[key] = section
+	}
+	return named
+}
+
+func flattenInto(leaves map[string]interface{}, path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			leaves[path] = v
+		}
+		for key, item := range v {
+			flattenInto(leaves, path+"."+key, item)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			leaves[path] = v
+		}
+		for i, item := range v {
+			flattenInto(leaves, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	default:
+		leaves[path] = v
+	}
+}
+
+func leafString(value interface{}) string {
+	data, err := encodeCanonical(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,72 @@
+package reporter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffReports(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude []string
+		change  func(r *SystemReport)
+		want    []string
+	}{
+		{"identical", nil, func(r *SystemReport) {}, nil},
+		{"modified value", nil, func(r *SystemReport) {
+			r.Reports[0].Sections["1"].Data.(*HostInfo).Kernel = "6.1.0-20-amd64"
+		}, []string{
+			`~ reports[web1].sections.host.data.kernel: "6.1.0-18-amd64" -> "6.1.0-20-amd64"`,
+		}},
+		{"removed array element", nil, func(r *SystemReport) {
+			section := r.Reports[0].Sections["6"]
+			section.Data = section.Data.([]ProcessInfo)[:1]
+			r.Reports[0].Sections["6"] = section
+		}, []string{
+			"- reports[web1].sections.processes.data[1].cpu_percent: 4.5",
+			"- reports[web1].sections.processes.data[1].memory_mb: 256",
+			`- reports[web1].sections.processes.data[1].name: "nginx: worker"`,
+			"- reports[web1].sections.processes.data[1].pid: 812",
+		}},
+		{"sections matched by name", nil, func(r *SystemReport) {
+			sections := r.Reports[0].Sections
+			// Та же секция под другим ключом
+			sections["9"] = sections["2"]
+			delete(sections, "2")
+		}, nil},
+		{"custom section added", nil, func(r *SystemReport) {
+			r.Reports[0].Sections["nginx"] = Section{Name: "nginx", Title: "NGINX", Data: map[string]string{"status": "up"}}
+		}, []string{
+			`+ reports[web1].sections.nginx.data.status: "up"`,
+			`+ reports[web1].sections.nginx.name: "nginx"`,
+			`+ reports[web1].sections.nginx.title: "NGINX"`,
+		}},
+		{"excluded fields ignored", DefaultHashExcludeFields, func(r *SystemReport) {
+			r.Generated = r.Generated.Add(time.Hour)
+			r.Reports[0].Sections["2"].Data.(*CPUInfo).UsagePercent = 99
+		}, nil},
+		{"volatile fields compared without exclusions", nil, func(r *SystemReport) {
+			r.Reports[0].Sections["2"].Data.(*CPUInfo).UsagePercent = 99
+		}, []string{
+			"~ reports[web1].sections.cpu.data.usage_percent: 12.5 -> 99",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := sampleReport()
+			tt.change(after)
+			changes, err := DiffReports(sampleReport(), after, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
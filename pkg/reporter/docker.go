@@ -0,0 +1,117 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultDockerSocket путь к сокету Docker Engine API по умолчанию
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// dockerAPIContainer элемент ответа GET /containers/json
+type dockerAPIContainer struct {
+	ID     string   `json:"Id"`
+	Names  []string `json:"Names"`
+	Image  string   `json:"Image"`
+	State  string   `json:"State"`
+	Status string   `json:"Status"`
+}
+
+// dockerClient минимальный клиент Docker Engine API через unix-сокет
+type dockerClient struct {
+	socketPath string
+	client     *http.Client
+}
+
+func newDockerClient(socketPath string) *dockerClient {
+	if socketPath == "" {
+		socketPath = DefaultDockerSocket
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return &dockerClient{
+		socketPath: socketPath,
+		client:     &http.Client{Transport: transport},
+	}
+}
+
+// listContainers возвращает все контейнеры, включая остановленные
+func (d *dockerClient) listContainers(ctx context.Context) ([]DockerContainer, error) {
+	if _, err := os.Stat(d.socketPath); err != nil {
+		return nil, fmt.Errorf("docker socket %s: %w", d.socketPath, ErrUnavailable)
+	}
+
+	// Хост в URL не используется: соединение всегда идет через сокет
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/json?all=1", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker request: %v", err)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return nil, fmt.Errorf("docker daemon at %s: %w", d.socketPath, ErrUnavailable)
+		}
+		return nil, fmt.Errorf("failed to query docker: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("docker API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var apiContainers []dockerAPIContainer
+	if err := json.NewDecoder(resp.Body).Decode(&apiContainers); err != nil {
+		return nil, fmt.Errorf("failed to decode docker response: %v", err)
+	}
+
+	containers := make([]DockerContainer, 0, len(apiContainers))
+	for _, c := range apiContainers {
+		containers = append(containers, convertDockerContainer(c))
+	}
+	return containers, nil
+}
+
+// convertDockerContainer приводит контейнер к формату отчета, как в `docker ps`
+func convertDockerContainer(c dockerAPIContainer) DockerContainer {
+	id := c.ID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+
+	name := ""
+	if len(c.Names) > 0 {
+		name = strings.TrimPrefix(c.Names[0], "/")
+	}
+
+	// Status имеет вид "Up 2 hours (healthy)" для запущенных контейнеров
+	uptime := ""
+	if c.State == "running" {
+		uptime = strings.TrimPrefix(c.Status, "Up ")
+		if i := strings.Index(uptime, " ("); i >= 0 {
+			uptime = uptime[:i]
+		}
+	}
+
+	return DockerContainer{
+		ContainerID: id,
+		Name:        name,
+		Image:       c.Image,
+		Status:      c.State,
+		Uptime:      uptime,
+	}
+}
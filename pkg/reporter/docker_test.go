@@ -0,0 +1,153 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDocker запускает httptest-сервер на unix-сокете во временном каталоге
+func fakeDocker(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen on %s: %v", socket, err)
+	}
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return socket
+}
+
+func dockerMux(containers string, status int) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "1" {
+			http.Error(w, "all=1 expected", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(containers))
+	})
+	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Containers":2,"ServerVersion":"24.0.7"}`))
+	})
+	return mux
+}
+
+const dockerContainersJSON = `[
+	{"Id":"4f1c2a9d8e7b6a5c4d3e2f1a","Names":["/web"],"Image":"nginx:1.25","State":"running","Status":"Up 2 hours (healthy)"},
+	{"Id":"abc","Names":["/job"],"Image":"busybox","State":"exited","Status":"Exited (0) 3 days ago"}
+]`
+
+func TestDockerListContainers(t *testing.T) {
+	socket := fakeDocker(t, dockerMux(dockerContainersJSON, http.StatusOK))
+
+	containers, err := newDockerClient(socket).listContainers(context.Background())
+	if err != nil {
+		t.Fatalf("listContainers: %v", err)
+	}
+	want := []DockerContainer{
+		{ContainerID: "4f1c2a9d8e7b", Name: "web", Image: "nginx:1.25", Status: "running", Uptime: "2 hours"},
+		{ContainerID: "abc", Name: "job", Image: "busybox", Status: "exited"},
+	}
+	if len(containers) != len(want) {
+		t.Fatalf("got %d containers, want %d", len(containers), len(want))
+	}
+	for i := range want {
+		if containers[i] != want[i] {
+			t.Errorf("container %d = %+v, want %+v", i, containers[i], want[i])
+		}
+	}
+}
+
+func TestDockerErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		socket      func(t *testing.T) string
+		unavailable bool
+		errContains string
+	}{
+		{
+			name:        "no socket",
+			socket:      func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing.sock") },
+			unavailable: true,
+		},
+		{
+			name: "daemon not listening",
+			socket: func(t *testing.T) string {
+				// Файл есть, но соединение отвергается
+				path := filepath.Join(t.TempDir(), "docker.sock")
+				if err := os.WriteFile(path, nil, 0600); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			},
+			unavailable: true,
+		},
+		{
+			name: "server error",
+			socket: func(t *testing.T) string {
+				return fakeDocker(t, dockerMux(`{"message":"daemon is shutting down"}`, http.StatusInternalServerError))
+			},
+			errContains: "status 500: {\"message\":\"daemon is shutting down\"}",
+		},
+		{
+			name: "not found",
+			socket: func(t *testing.T) string {
+				return fakeDocker(t, http.NotFoundHandler())
+			},
+			errContains: "status 404",
+		},
+		{
+			name: "invalid JSON",
+			socket: func(t *testing.T) string {
+				return fakeDocker(t, dockerMux(`{"not":"a list"}`, http.StatusOK))
+			},
+			errContains: "failed to decode docker response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newDockerClient(tt.socket(t)).listContainers(context.Background())
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, ErrUnavailable); got != tt.unavailable {
+				t.Errorf("errors.Is(err, ErrUnavailable) = %v, want %v (err: %v)", got, tt.unavailable, err)
+			}
+			if tt.errContains != "" && !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("error %q does not contain %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestDockerContextTimeout(t *testing.T) {
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+	socket := fakeDocker(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := newDockerClient(socket).listContainers(ctx)
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
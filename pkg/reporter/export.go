@@ -0,0 +1,133 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Exporter сохраняет отчет в файл определенного формата
+type Exporter interface {
+	// Format возвращает имя формата: json, text, html, markdown, csv
+	Format() string
+	// Export записывает отчет по пути path; path "-" - stdout для всех
+	// форматов, кроме csv, который принимает каталог.
+	Export(report *SystemReport, path string) error
+}
+
+// JSONExporter сохраняет отчет в JSON (см. SaveReportToJSON)
+func JSONExporter() Exporter {
+	return &funcExporter{format: "json", fn: func(report *SystemReport, path string) error {
+		if path == "-" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
+		return SaveReportToJSON(report, path)
+	}}
+}
+
+// TextExporter сохраняет отчет в текстовом виде (см. RenderText). При
+// opts == nil подсветка определяется по месту вывода.
+func TextExporter(opts *TextOptions) Exporter {
+	return &streamExporter{format: "text", render: func(report *SystemReport, w io.Writer) error {
+		if opts == nil {
+			return RenderText(report, w)
+		}
+		return RenderTextOptions(report, w, *opts)
+	}}
+}
+
+// HTMLExporter сохраняет отчет HTML-страницей (см. RenderHTML)
+func HTMLExporter() Exporter {
+	return &streamExporter{format: "html", render: RenderHTML}
+}
+
+// MarkdownExporter сохраняет отчет в Markdown (см. RenderMarkdown)
+func MarkdownExporter() Exporter {
+	return &streamExporter{format: "markdown", render: RenderMarkdown}
+}
+
+// CSVExporter сохраняет табличные секции в CSV-файлы каталога (см. WriteCSV).
+// Вывод в stdout ("-") не поддерживается: файлов несколько.
+func CSVExporter() Exporter {
+	return &funcExporter{format: "csv", fn: func(report *SystemReport, path string) error {
+		if path == "-" {
+			return fmt.Errorf("csv output is a directory of files and cannot be written to stdout")
+		}
+		return WriteCSV(report, path)
+	}}
+}
+
+// exporters встроенные форматы по именам
+var exporters = map[string]func() Exporter{
+	"json":     JSONExporter,
+	"text":     func() Exporter { return TextExporter(nil) },
+	"html":     HTMLExporter,
+	"markdown": MarkdownExporter,
+	"csv":      CSVExporter,
+}
+
+// ExportFormats возвращает имена встроенных форматов по алфавиту
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ExporterFor возвращает экспортер встроенного формата
+func ExporterFor(format string) (Exporter, error) {
+	newExporter, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, supported: %v", format, ExportFormats())
+	}
+	return newExporter(), nil
+}
+
+// ExporterSink приемник, сохраняющий отчет экспортером по пути path
+func ExporterSink(e Exporter, path string) Sink {
+	return NewSink(e.Format(), func(ctx context.Context, report *SystemReport) error {
+		return e.Export(report, path)
+	})
+}
+
+type funcExporter struct {
+	format string
+	fn     func(report *SystemReport, path string) error
+}
+
+func (e *funcExporter) Format() string { return e.format }
+
+func (e *funcExporter) Export(report *SystemReport, path string) error {
+	return e.fn(report, path)
+}
+
+// streamExporter экспортер формата, который пишется одним потоком
+type streamExporter struct {
+	format string
+	render func(report *SystemReport, w io.Writer) error
+}
+
+func (e *streamExporter) Format() string { return e.format }
+
+func (e *streamExporter) Export(report *SystemReport, path string) error {
+	if path == "" || path == "-" {
+		return e.render(report, os.Stdout)
+	}
+	var buf bytes.Buffer
+	if err := e.render(report, &buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	logf("Report saved to %s (%d bytes)\n", path, buf.Len())
+	return nil
+}
@@ -0,0 +1,161 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultHashExcludeFields поля, которые меняются от отчета к отчету без
+// изменения состояния системы, и поэтому не входят в ContentHash.
+//
+// Путь - ключи JSON через точку, начиная от SystemReport. Массивы
+// проходятся насквозь, "*" соответствует любому ключу, а секцию в
+// "sections" можно указать по имени сборщика вместо ключа секции.
+var DefaultHashExcludeFields = []string{
+	"generated",
+	"reports.timestamp",
+	"reports.report_number",
+	"reports.sections.host.data.uptime",
+	"reports.sections.cpu.data.usage_percent",
+	"reports.sections.cpu.data.load_average",
+	"reports.sections.memory.data.*.used_gb",
+	"reports.sections.memory.data.*.used_percent",
+	"reports.sections.memory.data.ram.available_gb",
+	"reports.sections.memory.data.ram.free_gb",
+	"reports.sections.memory.data.ram.cached_gb",
+	"reports.sections.memory.data.ram.buffers_mb",
+	"reports.sections.disk.data.used_gb",
+	"reports.sections.disk.data.used_percent",
+	"reports.sections.disk.data.free_gb",
+	"reports.sections.network.data.interfaces.statistics",
+	"reports.sections.processes.data.cpu_percent",
+	"reports.sections.processes.data.memory_mb",
+	"reports.sections.docker.data.uptime",
+	"reports.sections.security.data.days_since_upgrade",
+}
+
+// CanonicalJSON кодирует значение в каноническую форму JSON: ключи
+// объектов отсортированы, лишних пробелов нет, HTML-символы не
+// экранируются, числа сохраняют исходную запись. Одинаковые данные
+// всегда дают одинаковые байты.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	tree, err := canonicalTree(v)
+	if err != nil {
+		return nil, err
+	}
+	return encodeCanonical(tree)
+}
+
+// ContentHash вычисляет SHA-256 канонического JSON отчета без полей
+// exclude (см. DefaultHashExcludeFields). Отчеты с одинаковым
+// содержимым дают одинаковый хеш, что позволяет отбрасывать дубликаты
+// и замечать изменения.
+func ContentHash(report *SystemReport, exclude []string) (string, error) {
+	tree, err := canonicalTree(report)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range exclude {
+		removePath(tree, strings.Split(path, "."), "")
+	}
+	data, err := encodeCanonical(tree)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(data), nil
+}
+
+// SectionHashes вычисляет хеш каждой секции отчета хоста по ключу секции.
+// Пути exclude задаются так же, как для ContentHash; учитываются только
+// пути внутри "reports.".
+func SectionHashes(report *Report, exclude []string) (map[string]string, error) {
+	hashes := make(map[string]string, len(report.Sections))
+	for key, section := range report.Sections {
+		tree, err := canonicalTree(section)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash section %s: %v", key, err)
+		}
+		for _, path := range exclude {
+			segments := strings.Split(path, ".")
+			if len(segments) < 3 || segments[0] != "reports" || segments[1] != "sections" {
+				continue
+			}
+			if segments[2] != "*" && segments[2] != key && segments[2] != section.Name {
+				continue
+			}
+			removePath(tree, segments[3:], "")
+		}
+		data, err := encodeCanonical(tree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash section %s: %v", key, err)
+		}
+		hashes[key] = sha256Hex(data)
+	}
+	return hashes, nil
+}
+
+// canonicalTree переводит значение в дерево map/slice/json.Number
+func canonicalTree(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// encodeCanonical кодирует дерево; encoding/json сортирует ключи map
+func encodeCanonical(tree interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(tree); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// removePath удаляет из дерева поля по пути segments. parent - ключ,
+// под которым лежит node: внутри "sections" сегмент может совпадать с
+// именем секции вместо ее ключа.
+func removePath(node interface{}, segments []string, parent string) {
+	if len(segments) == 0 {
+		return
+	}
+	switch n := node.(type) {
+	case []interface{}:
+		for _, item := range n {
+			removePath(item, segments, parent)
+		}
+	case map[string]interface{}:
+		segment := segments[0]
+		for key, value := range n {
+			if !pathSegmentMatches(segment, key, value, parent) {
+				continue
+			}
+			if len(segments) == 1 {
+				delete(n, key)
+			} else {
+				removePath(value, segments[1:], key)
+			}
+		}
+	}
+}
+
+func pathSegmentMatches(segment, key string, value interface{}, parent string) bool {
+	if segment == "*" || segment == key {
+		return true
+	}
+	if parent != "sections" {
+		return false
+	}
+	section, ok := value.(map[string]interface{})
+	return ok && section["name"] == segment
+}
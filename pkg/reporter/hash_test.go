@@ -0,0 +1,185 @@
+package reporter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"sorted keys", map[string]interface{}{"b": 1, "a": map[string]int{"z": 1, "y": 2}}, `{"a":{"y":2,"z":1},"b":1}`},
+		{"struct fields sorted", LoadAvg{Load1: 1, Load5: 0.5, Load15: 0.25}, `{"15min":0.25,"1min":1,"5min":0.5}`},
+		{"html not escaped", map[string]string{"model": "<Xeon> & Co"}, `{"model":"<Xeon> & Co"}`},
+		{"numbers keep their form", json.RawMessage(`{"big":12345678901234567890,"f":1.50}`), `{"big":12345678901234567890,"f":1.50}`},
+		{"arrays keep order", []int{3, 1, 2}, `[3,1,2]`},
+		{"null", nil, `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON(tt.value)
+			if err != nil {
+				t.Fatalf("CanonicalJSON: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemovePath(t *testing.T) {
+	tree := func() interface{} {
+		var v interface{}
+		data := `{
+			"generated": "now",
+			"reports": [
+				{"host_id": "a", "sections": {
+					"2": {"name": "cpu", "data": {"model": "x", "usage_percent": 5}},
+					"3": {"name": "memory", "data": {"ram": {"total_gb": 16, "used_gb": 6}, "swap": {"total_gb": 2, "used_gb": 1}}},
+					"4": {"name": "disk", "data": [{"mountpoint": "/", "free_gb": 1}, {"mountpoint": "/var", "free_gb": 2}]}
+				}},
+				{"host_id": "b", "sections": {"2": {"name": "cpu", "data": {"model": "y", "usage_percent": 7}}}}
+			]
+		}`
+		if err := json.Unmarshal([]byte(data), &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	get := func(t *testing.T, v interface{}, path string) (interface{}, bool) {
+		t.Helper()
+		for _, segment := range strings.Split(path, ".") {
+			switch n := v.(type) {
+			case map[string]interface{}:
+				var ok bool
+				if v, ok = n[segment]; !ok {
+					return nil, false
+				}
+			case []interface{}:
+				i, err := strconv.Atoi(segment)
+				if err != nil || i >= len(n) {
+					return nil, false
+				}
+				v = n[i]
+			}
+		}
+		return v, true
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		removed []string // пути, которых не должно остаться
+		kept    []string // пути, которые должны остаться
+	}{
+		{"top level", "generated", []string{"generated"}, []string{"reports"}},
+		{"through arrays", "reports.host_id", []string{"reports.0.host_id", "reports.1.host_id"}, []string{"reports.0.sections"}},
+		{"section by name", "reports.sections.cpu.data.usage_percent",
+			[]string{"reports.0.sections.2.data.usage_percent", "reports.1.sections.2.data.usage_percent"},
+			[]string{"reports.0.sections.2.data.model"}},
+		{"section by key", "reports.sections.2.data.model",
+			[]string{"reports.0.sections.2.data.model"},
+			[]string{"reports.0.sections.2.data.usage_percent"}},
+		{"wildcard", "reports.sections.memory.data.*.used_gb",
+			[]string{"reports.0.sections.3.data.ram.used_gb", "reports.0.sections.3.data.swap.used_gb"},
+			[]string{"reports.0.sections.3.data.ram.total_gb"}},
+		{"array of sections data", "reports.sections.disk.data.free_gb",
+			[]string{"reports.0.sections.4.data.0.free_gb", "reports.0.sections.4.data.1.free_gb"},
+			[]string{"reports.0.sections.4.data.0.mountpoint"}},
+		{"name matches only inside sections", "reports.cpu", nil, []string{"reports.0.sections.2"}},
+		{"missing path", "reports.sections.docker.data.uptime", nil, []string{"reports.0.sections.2.data.model"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tree()
+			removePath(v, strings.Split(tt.path, "."), "")
+			for _, path := range tt.removed {
+				if _, ok := get(t, v, path); ok {
+					t.Errorf("%s still present", path)
+				}
+			}
+			for _, path := range tt.kept {
+				if _, ok := get(t, v, path); !ok {
+					t.Errorf("%s removed", path)
+				}
+			}
+		})
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	base, err := ContentHash(sampleReport(), DefaultHashExcludeFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(base) != 64 {
+		t.Fatalf("hash %q is not hex SHA-256", base)
+	}
+
+	tests := []struct {
+		name    string
+		exclude []string
+		change  func(r *SystemReport)
+		same    bool
+	}{
+		{"identical report", DefaultHashExcludeFields, func(r *SystemReport) {}, true},
+		{"generation time", DefaultHashExcludeFields, func(r *SystemReport) { r.Generated = r.Generated.Add(time.Hour) }, true},
+		{"cpu usage", DefaultHashExcludeFields, func(r *SystemReport) { r.Reports[0].Sections["2"].Data.(*CPUInfo).UsagePercent = 99 }, true},
+		{"process memory", DefaultHashExcludeFields, func(r *SystemReport) { r.Reports[0].Sections["6"].Data.([]ProcessInfo)[0].MemoryMB = 1 }, true},
+		{"kernel upgrade", DefaultHashExcludeFields, func(r *SystemReport) { r.Reports[0].Sections["1"].Data.(*HostInfo).Kernel = "6.1.0-20-amd64" }, false},
+		{"new process", DefaultHashExcludeFields, func(r *SystemReport) {
+			s := r.Reports[0].Sections["6"]
+			s.Data = append(s.Data.([]ProcessInfo), ProcessInfo{PID: 900, Name: "sshd"})
+			r.Reports[0].Sections["6"] = s
+		}, false},
+		{"no exclusions", nil, func(r *SystemReport) {}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := sampleReport()
+			tt.change(report)
+			hash, err := ContentHash(report, tt.exclude)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (hash == base) != tt.same {
+				t.Errorf("hash equal to base = %v, want %v", hash == base, tt.same)
+			}
+		})
+	}
+}
+
+func TestSectionHashes(t *testing.T) {
+	report := sampleReport()
+	before, err := SectionHashes(&report.Reports[0], DefaultHashExcludeFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != len(report.Reports[0].Sections) {
+		t.Fatalf("got %d hashes for %d sections", len(before), len(report.Reports[0].Sections))
+	}
+
+	report.Reports[0].Sections["2"].Data.(*CPUInfo).UsagePercent = 99
+	report.Reports[0].Sections["4"].Data.([]DiskInfo)[0].TotalGB = 200
+	after, err := SectionHashes(&report.Reports[0], DefaultHashExcludeFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changed []string
+	for key := range before {
+		if before[key] != after[key] {
+			changed = append(changed, key)
+		}
+	}
+	if !reflect.DeepEqual(changed, []string{"4"}) {
+		t.Errorf("changed sections = %v, want [4]", changed)
+	}
+}
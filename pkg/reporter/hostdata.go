@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// hostData типизированные данные встроенных секций отчета одного хоста.
+// Используется форматами метрик; отсутствующие или неудачные секции
+// остаются пустыми.
+type hostData struct {
+	HostID    string
+	Timestamp time.Time
+	Host      *HostInfo
+	CPU       *CPUInfo
+	Memory    *MemoryInfo
+	Disks     []DiskInfo
+	Network   *NetworkInfo
+	Processes []ProcessInfo
+	Docker    []DockerContainer
+	Security  *SecurityStatus
+	Sections  []Section // Все секции в порядке ключей
+}
+
+// builtinTitles заголовки встроенных секций для отчетов без поля name
+var builtinTitles = map[string]string{
+	"HOST INFORMATION":        SectionHost,
+	"CPU INFORMATION":         SectionCPU,
+	"MEMORY INFORMATION":      SectionMemory,
+	"DISK INFORMATION":        SectionDisk,
+	"NETWORK INFORMATION":     SectionNetwork,
+	"TOP PROCESSES BY MEMORY": SectionProcesses,
+	"DOCKER CONTAINERS":       SectionDocker,
+	"SECURITY STATUS":         SectionSecurity,
+}
+
+// sectionName возвращает имя сборщика секции
+func sectionName(section Section) string {
+	if section.Name != "" {
+		return section.Name
+	}
+	return builtinTitles[section.Title]
+}
+
+// newHostData разбирает секции отчета. Данные секций бывают как
+// исходными типами сборщиков, так и map после чтения отчета из JSON.
+func newHostData(report Report) hostData {
+	data := hostData{HostID: report.HostID, Timestamp: report.Timestamp}
+	for _, key := range sortedSectionKeys(report.Sections) {
+		section := report.Sections[key]
+		data.Sections = append(data.Sections, section)
+		if section.Status != "" || section.Data == nil {
+			continue
+		}
+
+		switch sectionName(section) {
+		case SectionHost:
+			data.Host = decodeSectionData[*HostInfo](section.Data)
+		case SectionCPU:
+			data.CPU = decodeSectionData[*CPUInfo](section.Data)
+		case SectionMemory:
+			data.Memory = decodeSectionData[*MemoryInfo](section.Data)
+		case SectionDisk:
+			data.Disks = decodeSectionData[[]DiskInfo](section.Data)
+		case SectionNetwork:
+			data.Network = decodeSectionData[*NetworkInfo](section.Data)
+		case SectionProcesses:
+			data.Processes = decodeSectionData[[]ProcessInfo](section.Data)
+		case SectionDocker:
+			data.Docker = decodeSectionData[[]DockerContainer](section.Data)
+		case SectionSecurity:
+			data.Security = decodeSectionData[*SecurityStatus](section.Data)
+		}
+	}
+	return data
+}
+
+// sortedSectionKeys возвращает ключи секций по возрастанию номера
+func sortedSectionKeys(sections map[string]Section) []string {
+	keys := make([]string, 0, len(sections))
+	for key := range sections {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA != nil || errB != nil {
+			return keys[i] < keys[j]
+		}
+		return a < b
+	})
+	return keys
+}
+
+// decodeSectionData приводит данные секции к типу T; при несовпадении
+// типа данные перекодируются через JSON. Ошибка дает нулевое значение.
+func decodeSectionData[T any](value interface{}) T {
+	if typed, ok := value.(T); ok {
+		return typed
+	}
+	var result T
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return result
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		var zero T
+		return zero
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package reporter
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+//go:embed templates/report.html
+var htmlTemplates embed.FS
+
+// htmlTemplate шаблон HTML-отчета: одна страница со встроенными стилями
+// и скриптами, без внешних ресурсов
+var htmlTemplate = template.Must(template.New("report.html").Funcs(template.FuncMap{
+	"sectionName": sectionName,
+	"gb":          formatGB,
+	"mb":          func(mb float64) string { return formatBytes(mb * 1024 * 1024) },
+	"percent":     formatPercent,
+	"uptime":      formatUptime,
+	"join":        strings.Join,
+	"time":        func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+	"level":       htmlLevel,
+	"bar":         htmlBar,
+	"service": func(status string) string {
+		switch status {
+		case SecurityActive:
+			return "ok"
+		case SecurityInactive, SecurityNotInstalled:
+			return "warn"
+		}
+		return ""
+	},
+	"json": func(v interface{}) string {
+		raw, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err.Error()
+		}
+		return string(raw)
+	},
+}).ParseFS(htmlTemplates, "templates/report.html"))
+
+// htmlPage данные шаблона HTML-отчета
+type htmlPage struct {
+	Title      string
+	APIVersion string
+	Generated  time.Time
+	Hosts      []htmlHost
+}
+
+type htmlHost struct {
+	hostData
+	ReportNumber int
+}
+
+// RenderHTML выводит отчет одной статической HTML-страницей: сводка по
+// хосту из HostInfo, шкалы заполнения памяти и дисков, сортируемые
+// таблицы процессов и интерфейсов. Несколько хостов отчета выводятся
+// на вкладках.
+func RenderHTML(report *SystemReport, w io.Writer) error {
+	page := htmlPage{
+		Title:      "System report",
+		APIVersion: report.APIVersion,
+		Generated:  report.Generated,
+	}
+	for _, r := range report.Reports {
+		page.Hosts = append(page.Hosts, htmlHost{hostData: newHostData(r), ReportNumber: r.ReportNumber})
+	}
+	if len(page.Hosts) == 1 {
+		page.Title = "System report: " + page.Hosts[0].HostID
+	}
+	if err := htmlTemplate.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to render HTML: %v", err)
+	}
+	return nil
+}
+
+// htmlLevel класс CSS процента загрузки по порогам по умолчанию
+func htmlLevel(percent float64) string {
+	switch {
+	case percent >= DefaultCriticalPercent:
+		return "crit"
+	case percent >= DefaultWarnPercent:
+		return "warn"
+	}
+	return ""
+}
+
+// htmlBar шкала заполнения с подписью процента
+func htmlBar(percent float64) template.HTML {
+	width := min(max(percent, 0), 100)
+	return template.HTML(fmt.Sprintf(`<div class="bar"><span class="%s" style="width: %.1f%%"></span><em>%s</em></div>`,
+		htmlLevel(percent), width, template.HTMLEscapeString(formatPercent(percent))))
+}
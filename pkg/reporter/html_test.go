@@ -0,0 +1,63 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	report := failedSectionsReport()
+	report.Reports[0].HostID = "web<1>"
+	report.Reports[0].Sections["1"].Data.(*HostInfo).Hostname = "<script>alert(1)</script>"
+	report.Reports[0].Sections["6"].Data.([]ProcessInfo)[1].Name = "nginx & <worker>"
+
+	var buf bytes.Buffer
+	if err := RenderHTML(report, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>System report: web&lt;1&gt;</title>",
+		"<h2>&lt;script&gt;alert(1)&lt;/script&gt;</h2>",
+		"<dt>Host ID</dt><dd>web&lt;1&gt;</dd>",
+		"<dt>Kernel</dt><dd>6.1.0-18-amd64</dd>",
+		"<dt>Model</dt><dd>Intel(R) Xeon(R) &lt;Gold&gt; &amp; Co</dd>",
+		"<td>nginx &amp; &lt;worker&gt;</td>",
+		"<td>/var/lib/data store</td>",
+		string(htmlBar(90)),
+		`<p class="error">timeout: collector timed out after 15s</p>`,
+		`<p class="error">error: permission &lt;denied&gt;</p>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	for _, raw := range []string{"<script>alert", "<worker>", "web<1>"} {
+		if strings.Contains(out, raw) {
+			t.Errorf("output contains unescaped %q", raw)
+		}
+	}
+	if n := strings.Count(out, `<section class="failed">`); n != 2 {
+		t.Errorf("failed sections = %d, want 2", n)
+	}
+}
+
+func TestHTMLBar(t *testing.T) {
+	tests := []struct {
+		percent float64
+		want    string
+	}{
+		{40, `<div class="bar"><span class="" style="width: 40.0%"></span><em>40.0%</em></div>`},
+		{85, `<div class="bar"><span class="warn" style="width: 85.0%"></span><em>85.0%</em></div>`},
+		{90, `<div class="bar"><span class="crit" style="width: 90.0%"></span><em>90.0%</em></div>`},
+		// Ширина шкалы ограничена, подпись - нет
+		{-5, `<div class="bar"><span class="" style="width: 0.0%"></span><em>-5.0%</em></div>`},
+		{150, `<div class="bar"><span class="crit" style="width: 100.0%"></span><em>150.0%</em></div>`},
+	}
+	for _, tt := range tests {
+		if got := string(htmlBar(tt.percent)); got != tt.want {
+			t.Errorf("htmlBar(%v) = %s, want %s", tt.percent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Команда schemagen записывает JSON Schema отчета, построенную по типам
+// Go (reporter.GenerateSchema). Запускается через go generate в
+// каталоге pkg/reporter: go run ./internal/schemagen report.schema.json
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"RPC-report/pkg/reporter"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: schemagen <output.json>")
+		os.Exit(2)
+	}
+	schema, err := reporter.GenerateSchema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(os.Args[1], schema, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
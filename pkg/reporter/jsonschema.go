@@ -0,0 +1,424 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaViolation нарушение схемы: JSON Pointer (RFC 6901) на значение
+// в документе и описание проблемы
+type SchemaViolation struct {
+	Pointer string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return pointer + ": " + v.Message
+}
+
+// ValidationError ошибка проверки отчета со списком всех нарушений схемы
+type ValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return "report does not match schema:\n  " + strings.Join(lines, "\n  ")
+}
+
+// Validate проверяет JSON-документ отчета по схеме ReportSchema. Если
+// документ не соответствует схеме, возвращает *ValidationError со всеми
+// нарушениями; ошибку разбора JSON возвращает как есть.
+func Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid JSON: unexpected data after the document")
+	}
+
+	schema, err := compiledReportSchema()
+	if err != nil {
+		return err
+	}
+	v := &schemaValidator{root: schema}
+	violations := v.validate(schema, doc, "")
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+var (
+	reportSchemaOnce   sync.Once
+	reportSchemaParsed map[string]interface{}
+	reportSchemaErr    error
+)
+
+func compiledReportSchema() (map[string]interface{}, error) {
+	reportSchemaOnce.Do(func() {
+		if err := json.Unmarshal(reportSchema, &reportSchemaParsed); err != nil {
+			reportSchemaErr = fmt.Errorf("invalid embedded schema: %v", err)
+		}
+	})
+	return reportSchemaParsed, reportSchemaErr
+}
+
+// schemaValidator проверяет документ по подмножеству JSON Schema 2020-12,
+// которое использует GenerateSchema: $ref на $defs, type, const, enum, not,
+// allOf, anyOf, if/then/else, properties, required, additionalProperties,
+// propertyNames, dependentRequired, items, minItems, minLength,
+// minProperties, minimum, pattern и format date-time
+type schemaValidator struct {
+	root     map[string]interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+func (v *schemaValidator) validate(schema map[string]interface{}, value interface{}, pointer string) []SchemaViolation {
+	var out []SchemaViolation
+	fail := func(format string, args ...interface{}) {
+		out = append(out, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			fail("%v", err)
+			return out
+		}
+		out = append(out, v.validate(target, value, pointer)...)
+	}
+
+	if typ, ok := schema["type"]; ok && !matchesType(typ, value) {
+		fail("expected %s, got %s", typeNames(typ), jsonTypeName(value))
+		return out
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, value) {
+		fail("must be %s", jsonText(c))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || jsonEqual(e, value)
+		}
+		if !found {
+			allowed := make([]string, len(enum))
+			for i, e := range enum {
+				allowed[i] = jsonText(e)
+			}
+			fail("%s is not one of %s", jsonText(value), strings.Join(allowed, ", "))
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok && len(v.validate(not, value, pointer)) == 0 {
+		if c, ok := not["const"]; ok {
+			fail("must not be %s", jsonText(c))
+		} else {
+			fail("must not match the schema in \"not\"")
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if s, ok := sub.(map[string]interface{}); ok {
+				out = append(out, v.validate(s, value, pointer)...)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		out = append(out, v.validateAnyOf(anyOf, value, pointer)...)
+	}
+	if cond, ok := schema["if"].(map[string]interface{}); ok {
+		branch := "else"
+		if len(v.validate(cond, value, pointer)) == 0 {
+			branch = "then"
+		}
+		if s, ok := schema[branch].(map[string]interface{}); ok {
+			out = append(out, v.validate(s, value, pointer)...)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		out = append(out, v.validateObject(schema, value, pointer)...)
+	case []interface{}:
+		if min, ok := schemaInt(schema, "minItems"); ok && len(value) < min {
+			fail("must have at least %d items", min)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				out = append(out, v.validate(items, item, pointer+"/"+strconv.Itoa(i))...)
+			}
+		}
+	case string:
+		if min, ok := schemaInt(schema, "minLength"); ok && utf8.RuneCountInString(value) < min {
+			if min == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters long", min)
+			}
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !v.match(pattern, value) {
+			fail("%q does not match pattern %s", value, pattern)
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				fail("%q is not an RFC 3339 date-time", value)
+			}
+		}
+	case json.Number:
+		if min, ok := schema["minimum"].(float64); ok {
+			if f, err := value.Float64(); err == nil && f < min {
+				fail("must be at least %v", min)
+			}
+		}
+	}
+	return out
+}
+
+func (v *schemaValidator) validateObject(schema map[string]interface{}, object map[string]interface{}, pointer string) []SchemaViolation {
+	var out []SchemaViolation
+	fail := func(format string, args ...interface{}) {
+		out = append(out, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+	}
+	if deps, ok := schema["dependentRequired"].(map[string]interface{}); ok {
+		for name, required := range deps {
+			if _, ok := object[name]; !ok {
+				continue
+			}
+			for _, dep := range required.([]interface{}) {
+				if _, ok := object[dep.(string)]; !ok {
+					fail("property %q requires property %q", name, dep)
+				}
+			}
+		}
+	}
+	if min, ok := schemaInt(schema, "minProperties"); ok && len(object) < min {
+		fail("must have at least %d properties", min)
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return lessSectionKey(names[i], names[j]) })
+
+	for _, name := range names {
+		child := pointer + "/" + escapePointer(name)
+		if propertyNames, ok := schema["propertyNames"].(map[string]interface{}); ok {
+			for _, violation := range v.validate(propertyNames, name, child) {
+				out = append(out, SchemaViolation{Pointer: child, Message: "invalid property name: " + violation.Message})
+			}
+		}
+		if sub, ok := properties[name].(map[string]interface{}); ok {
+			out = append(out, v.validate(sub, object[name], child)...)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				out = append(out, SchemaViolation{Pointer: child, Message: "unknown property"})
+			}
+		case map[string]interface{}:
+			out = append(out, v.validate(additional, object[name], child)...)
+		}
+	}
+	return out
+}
+
+// validateAnyOf проверяет anyOf. Если значение не подходит ни к одной
+// ветке, а ветка, кроме null, одна (поле, допускающее null), выводятся
+// нарушения этой ветки, чтобы указать на конкретное поле.
+func (v *schemaValidator) validateAnyOf(branches []interface{}, value interface{}, pointer string) []SchemaViolation {
+	var candidates [][]SchemaViolation
+	for _, branch := range branches {
+		s, ok := branch.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		violations := v.validate(s, value, pointer)
+		if len(violations) == 0 {
+			return nil
+		}
+		if s["type"] != "null" {
+			candidates = append(candidates, violations)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return []SchemaViolation{{Pointer: pointer, Message: "does not match any of the allowed schemas"}}
+}
+
+// resolve находит схему по локальной ссылке "#/$defs/Имя"
+func (v *schemaValidator) resolve(ref string) (map[string]interface{}, error) {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if ok {
+		defs, _ := v.root["$defs"].(map[string]interface{})
+		if target, ok := defs[name].(map[string]interface{}); ok {
+			return target, nil
+		}
+	}
+	return nil, fmt.Errorf("unresolved schema reference %q", ref)
+}
+
+func (v *schemaValidator) match(pattern, s string) bool {
+	re, ok := v.patterns[pattern]
+	if !ok {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return false
+		}
+		if v.patterns == nil {
+			v.patterns = make(map[string]*regexp.Regexp)
+		}
+		v.patterns[pattern] = re
+	}
+	return re.MatchString(s)
+}
+
+// matchesType проверяет ключевое слово type: строку или список типов
+func matchesType(typ interface{}, value interface{}) bool {
+	switch typ := typ.(type) {
+	case string:
+		return isJSONType(typ, value)
+	case []interface{}:
+		for _, t := range typ {
+			if name, ok := t.(string); ok && isJSONType(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isJSONType(name string, value interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == float64(int64(f))
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	}
+	return jsonTypeName(value) == name
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func typeNames(typ interface{}) string {
+	if list, ok := typ.([]interface{}); ok {
+		names := make([]string, len(list))
+		for i, t := range list {
+			names[i] = fmt.Sprint(t)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(typ)
+}
+
+func schemaInt(schema map[string]interface{}, key string) (int, bool) {
+	f, ok := schema[key].(float64)
+	return int(f), ok
+}
+
+// jsonEqual сравнивает значения схемы (числа float64) и документа
+// (числа json.Number)
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeJSON(a), normalizeJSON(b))
+}
+
+func normalizeJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		f, _ := value.Float64()
+		return f
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, v := range value {
+			out[i] = normalizeJSON(v)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			out[k] = normalizeJSON(v)
+		}
+		return out
+	}
+	return value
+}
+
+func jsonText(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
+
+// escapePointer экранирует сегмент JSON Pointer по RFC 6901
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// lessSectionKey упорядочивает имена свойств; числовые ключи секций -
+// по возрастанию номера
+func lessSectionKey(a, b string) bool {
+	x, errX := strconv.Atoi(a)
+	y, errY := strconv.Atoi(b)
+	if errX != nil || errY != nil {
+		return a < b
+	}
+	return x < y
+}
@@ -0,0 +1,200 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// reportDocument возвращает sampleReport в виде дерева JSON для изменения
+func reportDocument(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(sampleReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// docReport и docSection возвращают первый отчет документа и его секцию
+func docReport(doc map[string]interface{}) map[string]interface{} {
+	return doc["reports"].([]interface{})[0].(map[string]interface{})
+}
+
+func docSection(doc map[string]interface{}, key string) map[string]interface{} {
+	return docReport(doc)["sections"].(map[string]interface{})[key].(map[string]interface{})
+}
+
+func TestEmbeddedSchemaUpToDate(t *testing.T) {
+	generated, err := GenerateSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated, ReportSchema()) {
+		t.Error("report.schema.json is stale, run go generate ./pkg/reporter")
+	}
+}
+
+func TestValidateGeneratedReport(t *testing.T) {
+	report, err := New(nil).GenerateReport()
+	if err != nil {
+		t.Fatalf("GenerateReport: %v", err)
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(data); err != nil {
+		t.Errorf("generated report rejected: %v", err)
+	}
+}
+
+func TestValidateAccepts(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(doc map[string]interface{})
+	}{
+		{"sample report", func(doc map[string]interface{}) {}},
+		{"failed section", func(doc map[string]interface{}) {
+			section := docSection(doc, "3")
+			section["data"], section["status"], section["error"] = nil, SectionStatusTimeout, "collector timed out"
+		}},
+		{"custom section with any data", func(doc map[string]interface{}) {
+			docReport(doc)["sections"].(map[string]interface{})["nginx"] = map[string]interface{}{
+				"name": "nginx", "title": "NGINX", "data": map[string]interface{}{"workers": 4, "up": true},
+			}
+		}},
+		{"null slice", func(doc map[string]interface{}) { docSection(doc, "4")["data"] = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := reportDocument(t)
+			tt.change(doc)
+			data, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate(data); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(doc map[string]interface{})
+		want   []string // нарушения "указатель: сообщение"
+	}{
+		{
+			name: "wrong type",
+			change: func(doc map[string]interface{}) {
+				docSection(doc, "2")["data"].(map[string]interface{})["cores"] = "four"
+			},
+			want: []string{"/reports/0/sections/2/data/cores: expected integer, got string"},
+		},
+		{
+			name: "null in required number",
+			change: func(doc map[string]interface{}) {
+				docSection(doc, "4")["data"].([]interface{})[1].(map[string]interface{})["total_gb"] = nil
+			},
+			want: []string{"/reports/0/sections/4/data/1/total_gb: expected number, got null"},
+		},
+		{
+			name:   "missing required",
+			change: func(doc map[string]interface{}) { delete(docReport(doc), "host_id") },
+			want:   []string{`/reports/0: missing required property "host_id"`},
+		},
+		{
+			name:   "extra property",
+			change: func(doc map[string]interface{}) { docSection(doc, "1")["data"].(map[string]interface{})["extra"] = 1 },
+			want:   []string{"/reports/0/sections/1/data/extra: unknown property"},
+		},
+		{
+			name:   "bad date-time format",
+			change: func(doc map[string]interface{}) { docReport(doc)["timestamp"] = "2024-03-10 12:00" },
+			want:   []string{`/reports/0/timestamp: "2024-03-10 12:00" is not an RFC 3339 date-time`},
+		},
+		{
+			name:   "zero generation time",
+			change: func(doc map[string]interface{}) { doc["generated"] = zeroTime },
+			want:   []string{`/generated: must not be "0001-01-01T00:00:00Z"`},
+		},
+		{
+			name:   "no reports",
+			change: func(doc map[string]interface{}) { doc["reports"] = []interface{}{} },
+			want:   []string{"/reports: must have at least 1 items"},
+		},
+		{
+			name: "bad section key",
+			change: func(doc map[string]interface{}) {
+				sections := docReport(doc)["sections"].(map[string]interface{})
+				sections["07"] = sections["1"]
+			},
+			want: []string{`/reports/0/sections/07: invalid property name: "07" does not match pattern ^([1-9][0-9]*|[^0-9].*)$`},
+		},
+		{
+			name: "unknown status",
+			change: func(doc map[string]interface{}) {
+				docSection(doc, "3")["status"], docSection(doc, "3")["error"] = "broken", "x"
+			},
+			want: []string{`/reports/0/sections/3/status: "broken" is not one of "error", "timeout", "unavailable"`},
+		},
+		{
+			name:   "status without error",
+			change: func(doc map[string]interface{}) { docSection(doc, "3")["status"] = SectionStatusError },
+			want:   []string{`/reports/0/sections/3: property "status" requires property "error"`},
+		},
+		{
+			name: "all violations reported",
+			change: func(doc map[string]interface{}) {
+				doc["generated"] = "yesterday"
+				delete(docReport(doc), "host_id")
+			},
+			want: []string{
+				`/generated: "yesterday" is not an RFC 3339 date-time`,
+				`/reports/0: missing required property "host_id"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := reportDocument(t)
+			tt.change(doc)
+			data, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Validate(data)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate = %v, want *ValidationError", err)
+			}
+			var got []string
+			for _, v := range verr.Violations {
+				got = append(got, v.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidJSON(t *testing.T) {
+	for _, data := range []string{`{`, `{} {}`, `not json`} {
+		err := Validate([]byte(data))
+		var verr *ValidationError
+		if err == nil || errors.As(err, &verr) || !strings.Contains(err.Error(), "invalid JSON") {
+			t.Errorf("Validate(%q) = %v, want invalid JSON error", data, err)
+		}
+	}
+}
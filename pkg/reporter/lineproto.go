@@ -0,0 +1,192 @@
+package reporter
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricPoint одно измерение отчета: общая основа форматов InfluxDB и Graphite
+type metricPoint struct {
+	measurement string
+	host        string
+	tags        []string // Пары имя, значение, кроме host
+	series      []string // Части пути Graphite между измерением и полем
+	fields      []metricField
+	time        time.Time
+}
+
+type metricField struct {
+	name    string
+	value   float64
+	integer bool // Записывать как целое (суффикс i в line protocol)
+}
+
+// reportPoints раскладывает встроенные секции отчета на измерения
+// cpu, mem, swap, disk, net и process
+func reportPoints(report *SystemReport) []metricPoint {
+	var points []metricPoint
+	for _, r := range report.Reports {
+		h := newHostData(r)
+		ts := h.Timestamp
+		if ts.IsZero() {
+			ts = report.Generated
+		}
+		point := func(measurement string, tags, series []string, fields ...metricField) {
+			points = append(points, metricPoint{
+				measurement: measurement, host: h.HostID, tags: tags, series: series, fields: fields, time: ts,
+			})
+		}
+
+		if h.CPU != nil {
+			point("cpu", nil, nil,
+				intField("cores", float64(h.CPU.Cores)),
+				intField("threads", float64(h.CPU.Threads)),
+				floatField("usage_percent", h.CPU.UsagePercent),
+				floatField("load1", h.CPU.LoadAverage.Load1),
+				floatField("load5", h.CPU.LoadAverage.Load5),
+				floatField("load15", h.CPU.LoadAverage.Load15))
+		}
+
+		if h.Memory != nil {
+			ram, swap := h.Memory.RAM, h.Memory.Swap
+			point("mem", nil, nil,
+				intField("total_bytes", ram.TotalGB*bytesPerGB),
+				intField("available_bytes", ram.AvailableGB*bytesPerGB),
+				intField("used_bytes", ram.UsedGB*bytesPerGB),
+				intField("free_bytes", ram.FreeGB*bytesPerGB),
+				intField("cached_bytes", ram.CachedGB*bytesPerGB),
+				intField("buffers_bytes", ram.BuffersMB*1024*1024),
+				floatField("used_percent", ram.UsedPercent))
+			point("swap", nil, nil,
+				intField("total_bytes", swap.TotalGB*bytesPerGB),
+				intField("used_bytes", swap.UsedGB*bytesPerGB),
+				floatField("used_percent", swap.UsedPercent))
+		}
+
+		for _, d := range h.Disks {
+			point("disk", []string{"device", d.Device, "path", d.Mountpoint, "fstype", d.Filesystem}, []string{d.Mountpoint},
+				intField("total_bytes", d.TotalGB*bytesPerGB),
+				intField("used_bytes", d.UsedGB*bytesPerGB),
+				intField("free_bytes", d.FreeGB*bytesPerGB),
+				floatField("used_percent", d.UsedPercent))
+		}
+
+		if h.Network != nil {
+			for _, iface := range h.Network.Interfaces {
+				point("net", []string{"interface", iface.Name}, []string{iface.Name},
+					intField("bytes_sent", iface.Statistics.SentGB*bytesPerGB),
+					intField("bytes_recv", iface.Statistics.ReceivedGB*bytesPerGB))
+			}
+		}
+
+		for _, p := range h.Processes {
+			pid := strconv.Itoa(int(p.PID))
+			point("process", []string{"process_name", p.Name, "pid", pid}, []string{p.Name + "_" + pid},
+				intField("memory_bytes", p.MemoryMB*1024*1024),
+				floatField("cpu_percent", p.CPUPercent))
+		}
+	}
+	return points
+}
+
+func intField(name string, value float64) metricField {
+	return metricField{name: name, value: math.Round(value), integer: true}
+}
+
+func floatField(name string, value float64) metricField {
+	return metricField{name: name, value: value}
+}
+
+// WriteInfluxLineProtocol пишет отчет в формате InfluxDB line protocol:
+// по строке на измерение (cpu, mem, swap, disk, net, process) с меткой
+// host и временем отчета в наносекундах
+func WriteInfluxLineProtocol(report *SystemReport, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, p := range reportPoints(report) {
+		var fields []string
+		for _, f := range p.fields {
+			if math.IsNaN(f.value) || math.IsInf(f.value, 0) {
+				continue
+			}
+			value := strconv.FormatFloat(f.value, 'g', -1, 64)
+			if f.integer {
+				value = strconv.FormatInt(int64(f.value), 10) + "i"
+			}
+			fields = append(fields, influxKeyEscaper.Replace(f.name)+"="+value)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		bw.WriteString(influxMeasurementEscaper.Replace(p.measurement))
+		tags := append([]string{"host", p.host}, p.tags...)
+		for i := 0; i+1 < len(tags); i += 2 {
+			// Пустые значения меток line protocol не допускает
+			if tags[i+1] == "" {
+				continue
+			}
+			bw.WriteString("," + influxKeyEscaper.Replace(tags[i]) + "=" + influxKeyEscaper.Replace(tags[i+1]))
+		}
+		bw.WriteString(" " + strings.Join(fields, ",") + " " + strconv.FormatInt(p.time.UnixNano(), 10) + "\n")
+	}
+	return bw.Flush()
+}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "\n", `\n`)
+	influxKeyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", `\n`)
+)
+
+// DefaultGraphitePrefix первый компонент путей Graphite
+const DefaultGraphitePrefix = "reporter"
+
+// WriteGraphite пишет отчет в формате Graphite plaintext:
+// "<prefix>.<host>.<измерение>[.<серия>].<поле> <значение> <unix-время>".
+// Недопустимые в пути символы заменяются на "_", корень файловой
+// системы обозначается "root".
+func WriteGraphite(report *SystemReport, w io.Writer, prefix string) error {
+	if prefix == "" {
+		prefix = DefaultGraphitePrefix
+	}
+	bw := bufio.NewWriter(w)
+	for _, p := range reportPoints(report) {
+		parts := []string{prefix, graphiteComponent(p.host), p.measurement}
+		for _, s := range p.series {
+			parts = append(parts, graphiteComponent(s))
+		}
+		base := strings.Join(parts, ".")
+		ts := strconv.FormatInt(p.time.Unix(), 10)
+		for _, f := range p.fields {
+			if math.IsNaN(f.value) || math.IsInf(f.value, 0) {
+				continue
+			}
+			bw.WriteString(base + "." + f.name + " " + strconv.FormatFloat(f.value, 'f', -1, 64) + " " + ts + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// graphiteComponent приводит значение к допустимому компоненту пути
+func graphiteComponent(s string) string {
+	if s == "/" {
+		return "root"
+	}
+	s = strings.Trim(s, "/")
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
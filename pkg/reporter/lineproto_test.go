@@ -0,0 +1,158 @@
+package reporter
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// metricLines кодирует отчет и возвращает строки вывода
+func metricLines(t *testing.T, report *SystemReport, encode func(*SystemReport, *bytes.Buffer) error) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := encode(report, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		t.Errorf("output does not end with newline: %q", buf.String())
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// lineWithPrefix возвращает первую строку с заданным началом
+func lineWithPrefix(lines []string, prefix string) (string, bool) {
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+func TestWriteInfluxLineProtocol(t *testing.T) {
+	influx := func(r *SystemReport, buf *bytes.Buffer) error { return WriteInfluxLineProtocol(r, buf) }
+
+	tests := []struct {
+		name   string
+		change func(r *SystemReport)
+		want   []string // строки целиком
+		absent []string // начала строк, которых быть не должно
+	}{
+		{
+			name: "sample report",
+			want: []string{
+				"cpu,host=web1 cores=4i,threads=8i,usage_percent=12.5,load1=0.5,load5=0.4,load15=0.3 1710072000000000000",
+				"swap,host=web1 total_bytes=2147483648i,used_bytes=536870912i,used_percent=25 1710072000000000000",
+				`disk,host=web1,device=/dev/sdb1,path=/var/lib/data\ store,fstype=xfs total_bytes=536870912000i,used_bytes=483183820800i,free_bytes=53687091200i,used_percent=90 1710072000000000000`,
+				"net,host=web1,interface=eth0 bytes_sent=1610612736i,bytes_recv=3489660928i 1710072000000000000",
+				`process,host=web1,process_name=nginx:\ worker,pid=812 memory_bytes=268435456i,cpu_percent=4.5 1710072000000000000`,
+			},
+		},
+		{
+			name: "tag escaping",
+			change: func(r *SystemReport) {
+				r.Reports[0].HostID = "web 1,dc=a"
+				r.Reports[0].Sections["6"].Data.([]ProcessInfo)[0].Name = "a=b,c\nd"
+			},
+			want: []string{
+				`process,host=web\ 1\,dc\=a,process_name=a\=b\,c\nd,pid=1 memory_bytes=12582912i,cpu_percent=0.1 1710072000000000000`,
+			},
+		},
+		{
+			name:   "empty tag value skipped",
+			change: func(r *SystemReport) { r.Reports[0].Sections["4"].Data.([]DiskInfo)[0].Filesystem = "" },
+			want: []string{
+				"disk,host=web1,device=/dev/sda1,path=/ total_bytes=107374182400i,used_bytes=42949672960i,free_bytes=64424509440i,used_percent=40 1710072000000000000",
+			},
+		},
+		{
+			name: "non-finite fields skipped",
+			change: func(r *SystemReport) {
+				cpu := r.Reports[0].Sections["2"].Data.(*CPUInfo)
+				cpu.UsagePercent, cpu.LoadAverage.Load1 = math.NaN(), math.Inf(1)
+			},
+			want: []string{"cpu,host=web1 cores=4i,threads=8i,load5=0.4,load15=0.3 1710072000000000000"},
+		},
+		{
+			name:   "missing sections",
+			change: func(r *SystemReport) { delete(r.Reports[0].Sections, "2"); delete(r.Reports[0].Sections, "6") },
+			absent: []string{"cpu,", "process,"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := sampleReport()
+			if tt.change != nil {
+				tt.change(report)
+			}
+			lines := metricLines(t, report, influx)
+			for _, want := range tt.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf("missing line %q in:\n%s", want, strings.Join(lines, "\n"))
+				}
+			}
+			for _, prefix := range tt.absent {
+				if line, ok := lineWithPrefix(lines, prefix); ok {
+					t.Errorf("unexpected line %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteGraphite(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{"default prefix", "", []string{
+			"reporter.web1.cpu.usage_percent 12.5 1710072000",
+			"reporter.web1.disk.root.used_bytes 42949672960 1710072000",
+			"reporter.web1.disk.var_lib_data_store.used_percent 90 1710072000",
+			"reporter.web1.net.eth0.bytes_recv 3489660928 1710072000",
+			"reporter.web1.process.nginx__worker_812.cpu_percent 4.5 1710072000",
+		}},
+		{"custom prefix", "servers", []string{
+			"servers.web1.mem.total_bytes 17179869184 1710072000",
+			"servers.web1.swap.used_percent 25 1710072000",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := metricLines(t, sampleReport(), func(r *SystemReport, buf *bytes.Buffer) error {
+				return WriteGraphite(r, buf, tt.prefix)
+			})
+			if len(lines) != 30 {
+				t.Errorf("got %d lines, want 30", len(lines))
+			}
+			for _, want := range tt.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf("missing line %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphiteComponent(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"/", "root"},
+		{"/var/lib/data store", "var_lib_data_store"},
+		{"/mnt/", "mnt"},
+		{"eth0.100", "eth0_100"},
+		{"nginx: worker_812", "nginx__worker_812"},
+		{"web-1", "web-1"},
+		{"диск", "____"},
+		{"", "_"},
+	}
+	for _, tt := range tests {
+		if got := graphiteComponent(tt.in); got != tt.want {
+			t.Errorf("graphiteComponent(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
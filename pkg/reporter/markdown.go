@@ -0,0 +1,137 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RenderMarkdown выводит отчет в Markdown (GitHub Flavored): заголовок
+// на хост, секции со сводками и таблицами. Подходит для вики и
+// описаний merge request.
+func RenderMarkdown(report *SystemReport, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for i, r := range report.Reports {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		renderMarkdownHost(bw, r)
+	}
+	return bw.Flush()
+}
+
+func renderMarkdownHost(w *bufio.Writer, r Report) {
+	h := newHostData(r)
+	fmt.Fprintf(w, "# Report for %s\n", mdEscape(h.HostID))
+	if !h.Timestamp.IsZero() {
+		fmt.Fprintf(w, "\nReport #%d, %s\n", r.ReportNumber, h.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	}
+
+	for _, section := range h.Sections {
+		fmt.Fprintf(w, "\n## %s\n\n", mdEscape(section.Title))
+		if section.Status != "" {
+			fmt.Fprintf(w, "> **%s**: %s\n", section.Status, mdEscape(section.Error))
+			continue
+		}
+
+		switch sectionName(section) {
+		case SectionHost:
+			if info := h.Host; info != nil {
+				mdFields(w, "Hostname", info.Hostname, "OS", info.OS, "Kernel", info.Kernel,
+					"Uptime", formatUptime(info.Uptime.Hours))
+			}
+		case SectionCPU:
+			if info := h.CPU; info != nil {
+				load := info.LoadAverage
+				mdFields(w, "Model", info.Model,
+					"Cores", fmt.Sprintf("%d (%d threads)", info.Cores, info.Threads),
+					"Usage", formatPercent(info.UsagePercent),
+					"Load average", fmt.Sprintf("%.2f %.2f %.2f", load.Load1, load.Load5, load.Load15))
+			}
+		case SectionMemory:
+			if info := h.Memory; info != nil {
+				ram, swap := info.RAM, info.Swap
+				mdTable(w, []string{"", "Total", "Used", "Used %", "Available"}, [][]string{
+					{"RAM", formatGB(ram.TotalGB), formatGB(ram.UsedGB), formatPercent(ram.UsedPercent), formatGB(ram.AvailableGB)},
+					{"Swap", formatGB(swap.TotalGB), formatGB(swap.UsedGB), formatPercent(swap.UsedPercent), ""},
+				})
+			}
+		case SectionDisk:
+			var rows [][]string
+			for _, d := range h.Disks {
+				rows = append(rows, []string{d.Device, d.Mountpoint, d.Filesystem,
+					formatGB(d.TotalGB), formatGB(d.UsedGB), formatGB(d.FreeGB), formatPercent(d.UsedPercent)})
+			}
+			mdTable(w, []string{"Device", "Mountpoint", "FS", "Size", "Used", "Free", "Use %"}, rows)
+		case SectionNetwork:
+			if h.Network != nil {
+				var rows [][]string
+				for _, iface := range h.Network.Interfaces {
+					rows = append(rows, []string{iface.Name, iface.MAC, strings.Join(iface.IPs, ", "),
+						formatGB(iface.Statistics.SentGB), formatGB(iface.Statistics.ReceivedGB)})
+				}
+				mdTable(w, []string{"Interface", "MAC", "Addresses", "Sent", "Received"}, rows)
+			}
+		case SectionProcesses:
+			var rows [][]string
+			for _, p := range h.Processes {
+				rows = append(rows, []string{strconv.Itoa(int(p.PID)), p.Name,
+					formatBytes(p.MemoryMB * 1024 * 1024), formatPercent(p.CPUPercent)})
+			}
+			mdTable(w, []string{"PID", "Name", "Memory", "CPU %"}, rows)
+		case SectionDocker:
+			var rows [][]string
+			for _, c := range h.Docker {
+				rows = append(rows, []string{c.ContainerID, c.Name, c.Image, c.Status, c.Uptime})
+			}
+			mdTable(w, []string{"Container ID", "Name", "Image", "Status", "Uptime"}, rows)
+		case SectionSecurity:
+			if s := h.Security; s != nil {
+				mdFields(w, "Fail2ban", s.Fail2ban, "UFW", s.UfwStatus, "Last upgrade", s.LastUpdates,
+					"SSH failed logins", strconv.Itoa(s.SSHFailedAttempts))
+			}
+		default:
+			raw, err := json.MarshalIndent(section.Data, "", "  ")
+			if err != nil {
+				raw = []byte(err.Error())
+			}
+			fmt.Fprintf(w, "```json\n%s\n```\n", raw)
+		}
+	}
+}
+
+// mdFields выводит пары имя, значение таблицей из двух колонок
+func mdFields(w *bufio.Writer, pairs ...string) {
+	var rows [][]string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		rows = append(rows, []string{"**" + pairs[i] + "**", pairs[i+1]})
+	}
+	mdTable(w, []string{"Field", "Value"}, rows)
+}
+
+func mdTable(w *bufio.Writer, header []string, rows [][]string) {
+	if len(rows) == 0 {
+		w.WriteString("_No data_\n")
+		return
+	}
+	line := func(cells []string) {
+		w.WriteString("|")
+		for _, cell := range cells {
+			w.WriteString(" " + mdEscape(cell) + " |")
+		}
+		w.WriteString("\n")
+	}
+	line(header)
+	w.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		line(row)
+	}
+}
+
+// mdEscaper экранирует символы, ломающие таблицы и разметку
+var mdEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "<", "&lt;", ">", "&gt;")
+
+func mdEscape(s string) string { return mdEscaper.Replace(s) }
@@ -0,0 +1,50 @@
+package reporter
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	report := failedSectionsReport()
+	report.Reports[0].Sections["6"].Data.([]ProcessInfo)[1].Name = "nginx | worker\n<pool>"
+
+	var buf bytes.Buffer
+	if err := RenderMarkdown(report, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	lines := strings.Split(out, "\n")
+	for _, want := range []string{
+		"# Report for web1",
+		"Report #1, 2024-03-10 12:00:00 UTC",
+		"## HOST INFORMATION",
+		"| **Hostname** | web1 |",
+		"| **Kernel** | 6.1.0-18-amd64 |",
+		"| **Model** | Intel(R) Xeon(R) &lt;Gold&gt; & Co |",
+		"| /dev/sdb1 | /var/lib/data store | xfs | 500.0 GiB | 450.0 GiB | 50.0 GiB | 90.0% |",
+		`| 812 | nginx \| worker &lt;pool&gt; | 256.0 MiB | 4.5% |`,
+		"> **timeout**: collector timed out after 15s",
+		"> **error**: permission &lt;denied&gt;",
+	} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("missing line %q in:\n%s", want, out)
+		}
+	}
+
+	// У неудавшейся секции нет таблицы
+	memory := strings.Index(out, "## MEMORY INFORMATION")
+	if disk := strings.Index(out, "## DISK INFORMATION"); memory < 0 || disk < memory {
+		t.Error("sections are out of order")
+	} else if strings.Contains(out[memory:disk], "|") {
+		t.Errorf("failed memory section rendered a table: %q", out[memory:disk])
+	}
+}
+
+func TestRenderMarkdownWriteError(t *testing.T) {
+	if err := RenderMarkdown(sampleReport(), &failingWriter{limit: 100}); err == nil {
+		t.Error("RenderMarkdown to a failing writer returned nil")
+	}
+}
@@ -0,0 +1,194 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// InfluxConfig отправка отчета в InfluxDB в формате line protocol
+type InfluxConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Адрес: tcp://host:8094 или udp://host:8089 (Telegraf, listener
+	// InfluxDB 1.x), http(s)://host:8086/write?db=... или
+	// http(s)://host:8086/api/v2/write?org=...&bucket=...
+	URL     string        `yaml:"url" toml:"url"`
+	Token   string        `yaml:"token" toml:"token"`     // Токен InfluxDB 2.x для HTTP
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"` // 0 - Config.Timeout
+}
+
+// GraphiteConfig отправка отчета в Graphite (carbon) в формате plaintext
+type GraphiteConfig struct {
+	Enabled bool          `yaml:"enabled" toml:"enabled"`
+	URL     string        `yaml:"url" toml:"url"`       // tcp://host:2003 или udp://host:2003
+	Prefix  string        `yaml:"prefix" toml:"prefix"` // Первый компонент путей
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// udpPacketSize предел датаграммы: строки не разрезаются и укладываются
+// в пакеты не больше этого размера, чтобы избежать IP-фрагментации
+const udpPacketSize = 1400
+
+// InfluxSink отправляет отчет в InfluxDB по Config.Influx
+func InfluxSink(config *Config) Sink {
+	return NewSink("influx", func(ctx context.Context, report *SystemReport) error {
+		var buf bytes.Buffer
+		if err := WriteInfluxLineProtocol(report, &buf); err != nil {
+			return err
+		}
+		return sendMetrics(ctx, config, config.Influx.URL, config.Influx.Token, config.Influx.Timeout, buf.Bytes())
+	})
+}
+
+// GraphiteSink отправляет отчет в Graphite по Config.Graphite
+func GraphiteSink(config *Config) Sink {
+	return NewSink("graphite", func(ctx context.Context, report *SystemReport) error {
+		var buf bytes.Buffer
+		if err := WriteGraphite(report, &buf, config.Graphite.Prefix); err != nil {
+			return err
+		}
+		return sendMetrics(ctx, config, config.Graphite.URL, "", config.Graphite.Timeout, buf.Bytes())
+	})
+}
+
+// InfluxFileSink сохраняет отчет в файл в формате line protocol
+func InfluxFileSink(filename string) Sink {
+	return encodedFileSink("influx-file", filename, WriteInfluxLineProtocol)
+}
+
+// GraphiteFileSink сохраняет отчет в файл в формате Graphite plaintext
+func GraphiteFileSink(filename, prefix string) Sink {
+	return encodedFileSink("graphite-file", filename, func(report *SystemReport, w io.Writer) error {
+		return WriteGraphite(report, w, prefix)
+	})
+}
+
+func encodedFileSink(name, filename string, encode func(*SystemReport, io.Writer) error) Sink {
+	return NewSink(name, func(ctx context.Context, report *SystemReport) error {
+		var buf bytes.Buffer
+		if err := encode(report, &buf); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write file: %v", err)
+		}
+		logf("Metrics saved to %s (%d bytes)\n", filename, buf.Len())
+		return nil
+	})
+}
+
+// sendMetrics отправляет данные по схеме адреса: tcp, udp или http(s),
+// с повторами по Config.Retry
+func sendMetrics(ctx context.Context, config *Config, target, token string, timeout time.Duration, data []byte) error {
+	if timeout <= 0 {
+		timeout = config.Timeout
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %v", target, err)
+	}
+
+	var send func() error
+	switch u.Scheme {
+	case "tcp", "udp":
+		send = func() error { return writeSocket(ctx, u.Scheme, u.Host, timeout, data) }
+	case "http", "https":
+		client, err := newHTTPClient(metricsTLS(config.TLS), timeout, target)
+		if err != nil {
+			return err
+		}
+		send = func() error { return postMetrics(ctx, client, target, token, data) }
+	default:
+		return fmt.Errorf("unsupported URL scheme %q, use tcp, udp, http or https", u.Scheme)
+	}
+
+	logf("Sending metrics to %s (%d bytes)...\n", target, len(data))
+	if err := sendWithRetry(ctx, config.Retry, breakerFor(target), send); err != nil {
+		return err
+	}
+	logf("Metrics successfully sent to %s\n", target)
+	return nil
+}
+
+// metricsTLS возвращает настройки TLS для приемника метрик: CA, клиентский
+// сертификат, минимальная версия и insecure_skip_verify берутся из настроек
+// API, а server_name и required относятся только к адресу API
+func metricsTLS(config TLSConfig) TLSConfig {
+	config.ServerName = ""
+	config.Required = false
+	return config
+}
+
+// writeSocket пишет данные в TCP-соединение или пачкой UDP-датаграмм
+func writeSocket(ctx context.Context, network, addr string, timeout time.Duration, data []byte) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", addr, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return fmt.Errorf("failed to set deadline for %s: %v", addr, err)
+	}
+
+	if network == "tcp" {
+		if _, err := conn.Write(data); err != nil {
+			return fmt.Errorf("failed to write to %s: %v", addr, err)
+		}
+		return nil
+	}
+
+	for len(data) > 0 {
+		n := packetEnd(data, udpPacketSize)
+		if _, err := conn.Write(data[:n]); err != nil {
+			return fmt.Errorf("failed to write to %s: %v", addr, err)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// packetEnd возвращает длину префикса data из целых строк не длиннее
+// limit; строка длиннее limit уходит отдельным пакетом
+func packetEnd(data []byte, limit int) int {
+	if len(data) <= limit {
+		return len(data)
+	}
+	if i := bytes.LastIndexByte(data[:limit], '\n'); i >= 0 {
+		return i + 1
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1
+	}
+	return len(data)
+}
+
+// postMetrics отправляет line protocol в HTTP write API InfluxDB
+func postMetrics(ctx context.Context, client *http.Client, target, token string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp)
+	}
+	// Запись принята; остаток тела читается ради keep-alive
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
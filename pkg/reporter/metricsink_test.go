@@ -0,0 +1,271 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPacketEnd(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		limit int
+		want  int
+	}{
+		{"fits", "a 1\nb 2\n", 10, 8},
+		{"exact limit", "a 1\nb 2\n", 8, 8},
+		{"split at last newline", "a 1\nb 2\nc 3\n", 9, 8},
+		{"first line only", "a 1\nbbbbbbbb 2\n", 8, 4},
+		{"long first line alone", "aaaaaaaaaa 1\nb 2\n", 8, 13},
+		{"long line without newline", "aaaaaaaaaa 1", 8, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := packetEnd([]byte(tt.data), tt.limit); got != tt.want {
+				t.Errorf("packetEnd(%q, %d) = %d, want %d", tt.data, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+// metricsTestConfig конфигурация без повторов, чтобы ошибки возвращались сразу
+func metricsTestConfig() *Config {
+	config := DefaultConfig()
+	config.Timeout = 5 * time.Second
+	config.Retry = RetryConfig{MaxAttempts: 1}
+	return config
+}
+
+// metricsPayload строки line protocol общей длиной больше нескольких
+// UDP-пакетов, включая одну строку длиннее пакета
+func metricsPayload() []byte {
+	var buf bytes.Buffer
+	for i := 0; i < 100; i++ {
+		buf.WriteString("cpu,host=web1 usage_percent=12.5 1710072000000000000\n")
+	}
+	buf.WriteString("process,host=web1,process_name=" + strings.Repeat("x", 2*udpPacketSize) + " cpu_percent=1 1710072000000000000\n")
+	buf.WriteString("mem,host=web1 used_percent=37.5 1710072000000000000\n")
+	return buf.Bytes()
+}
+
+func TestSendMetricsTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	data := metricsPayload()
+	if err := sendMetrics(context.Background(), metricsTestConfig(), "tcp://"+ln.Addr().String(), "", 0, data); err != nil {
+		t.Fatalf("sendMetrics: %v", err)
+	}
+	if got := <-received; !bytes.Equal(got, data) {
+		t.Errorf("listener got %d bytes, want %d", len(got), len(data))
+	}
+}
+
+func TestSendMetricsUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	data := metricsPayload()
+	if err := sendMetrics(context.Background(), metricsTestConfig(), "udp://"+conn.LocalAddr().String(), "", 0, data); err != nil {
+		t.Fatalf("sendMetrics: %v", err)
+	}
+
+	var packets [][]byte
+	var got []byte
+	buf := make([]byte, 64*1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(got) < len(data) {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read after %d of %d bytes: %v", len(got), len(data), err)
+		}
+		packet := append([]byte(nil), buf[:n]...)
+		packets = append(packets, packet)
+		got = append(got, packet...)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("reassembled packets differ from sent data")
+	}
+
+	oversized := 0
+	for i, packet := range packets {
+		if !bytes.HasSuffix(packet, []byte("\n")) {
+			t.Errorf("packet %d splits a line: %q", i, packet[len(packet)-20:])
+		}
+		if len(packet) > udpPacketSize {
+			oversized++
+			if bytes.Count(packet, []byte("\n")) != 1 {
+				t.Errorf("packet %d is %d bytes with several lines", i, len(packet))
+			}
+		}
+	}
+	if oversized != 1 || len(packets) < 4 {
+		t.Errorf("got %d packets, %d oversized; want at least 4 with 1 oversized", len(packets), oversized)
+	}
+}
+
+// influxReceiver принимает запросы HTTP write API и запоминает их
+type influxReceiver struct {
+	mu     sync.Mutex
+	header http.Header
+	body   []byte
+	status int
+}
+
+func (rcv *influxReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.header = r.Header.Clone()
+	rcv.body, _ = io.ReadAll(r.Body)
+	if rcv.status != 0 {
+		w.WriteHeader(rcv.status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestSendMetricsHTTP(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tests := []struct {
+		name    string
+		https   bool
+		tls     TLSConfig
+		token   string
+		status  int
+		wantErr string
+	}{
+		{name: "token header", token: "s3cr3t"},
+		{name: "no token"},
+		{name: "server error", status: http.StatusBadRequest, wantErr: "400"},
+		{name: "api tls.required does not apply", tls: TLSConfig{Required: true}},
+		{name: "https with configured CA", https: true, token: "s3cr3t", tls: TLSConfig{CAFile: pki.caFile}},
+		{name: "api server_name ignored", https: true, tls: TLSConfig{CAFile: pki.caFile, ServerName: "api.example.com"}},
+		{name: "mTLS client certificate", https: true, tls: TLSConfig{CAFile: pki.caFile, CertFile: pki.certFile, KeyFile: pki.keyFile}},
+		{name: "https without CA", https: true, wantErr: "certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &influxReceiver{status: tt.status}
+			server := httptest.NewUnstartedServer(receiver)
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			if tt.https {
+				server.TLS = &tls.Config{Certificates: []tls.Certificate{pki.server}, ClientCAs: pki.pool}
+				if tt.tls.CertFile != "" {
+					server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+				server.StartTLS()
+			} else {
+				server.Start()
+			}
+			defer server.Close()
+
+			config := metricsTestConfig()
+			config.TLS = tt.tls
+			target := server.URL + "/api/v2/write?org=ops&bucket=hosts"
+			data := []byte("cpu,host=web1 usage_percent=12.5 1710072000000000000\n")
+			err := sendMetrics(context.Background(), config, target, tt.token, 0, data)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("sendMetrics: %v", err)
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			receiver.mu.Lock()
+			defer receiver.mu.Unlock()
+			if !bytes.Equal(receiver.body, data) {
+				t.Errorf("body = %q, want %q", receiver.body, data)
+			}
+			if got := receiver.header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			want := ""
+			if tt.token != "" {
+				want = "Token " + tt.token
+			}
+			if got := receiver.header.Get("Authorization"); got != want {
+				t.Errorf("Authorization = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSendMetricsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		wantErr string
+	}{
+		{"unsupported scheme", "ftp://127.0.0.1:21", `unsupported URL scheme "ftp"`},
+		{"bad URL", "tcp://[::1", "invalid URL"},
+		{"connection refused", "tcp://127.0.0.1:1", "failed to connect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sendMetrics(context.Background(), metricsTestConfig(), tt.target, "", time.Second, []byte("x 1\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMetricsFileSinks(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		sink   Sink
+		file   string
+		prefix string
+	}{
+		{InfluxFileSink(filepath.Join(dir, "metrics.lp")), "metrics.lp", "cpu,host=web1 cores=4i,"},
+		{GraphiteFileSink(filepath.Join(dir, "metrics.txt"), "servers"), "metrics.txt", "servers.web1.cpu.cores 4 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sink.Name(), func(t *testing.T) {
+			if err := tt.sink.Write(context.Background(), sampleReport()); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), tt.prefix) {
+				t.Errorf("%s starts with %.40q, want %q", tt.file, data, tt.prefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOTLPEndpoint адрес OTLP/HTTP-приемника метрик локального коллектора
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/metrics"
+
+// otlpScopeName имя инструментирующей библиотеки в OTLP
+const otlpScopeName = "RPC-report/reporter"
+
+// OTLPConfig экспорт метрик в OpenTelemetry Collector по OTLP/HTTP (JSON)
+type OTLPConfig struct {
+	Enabled     bool              `yaml:"enabled" toml:"enabled"`
+	Endpoint    string            `yaml:"endpoint" toml:"endpoint"`       // URL приемника, обычно .../v1/metrics
+	Headers     map[string]string `yaml:"headers" toml:"headers"`         // Дополнительные заголовки, например авторизация
+	Timeout     time.Duration     `yaml:"timeout" toml:"timeout"`         // Таймаут запроса; 0 - Config.Timeout
+	Compression string            `yaml:"compression" toml:"compression"` // "none", "gzip" или "zstd"
+	TLS         TLSConfig         `yaml:"tls" toml:"tls"`
+}
+
+// DefaultOTLPConfig возвращает настройки экспорта OTLP по умолчанию (выключен)
+func DefaultOTLPConfig() OTLPConfig {
+	return OTLPConfig{
+		Endpoint:    DefaultOTLPEndpoint,
+		Compression: CompressionNone,
+		TLS:         TLSConfig{MinVersion: "1.2"},
+	}
+}
+
+// OTLPSink отправляет метрики отчета в OpenTelemetry Collector
+func OTLPSink(config *Config) Sink {
+	return NewSink("otlp", func(ctx context.Context, report *SystemReport) error {
+		return ExportOTLP(ctx, config, report)
+	})
+}
+
+// ExportOTLP переводит отчет в метрики OpenTelemetry и отправляет их на
+// Config.OTLP.Endpoint с повторами по Config.Retry
+func ExportOTLP(ctx context.Context, config *Config, report *SystemReport) error {
+	otlp := config.OTLP
+	jsonData, err := json.Marshal(NewOTLPMetrics(report, config.AgentName))
+	if err != nil {
+		return fmt.Errorf("failed to marshal OTLP metrics: %v", err)
+	}
+	body, encoding, err := compressBody(otlp.Compression, DefaultCompressionThreshold, jsonData)
+	if err != nil {
+		return err
+	}
+
+	timeout := otlp.Timeout
+	if timeout <= 0 {
+		timeout = config.Timeout
+	}
+	client, err := newHTTPClient(otlp.TLS, timeout, otlp.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	logf("Exporting metrics to OTLP endpoint %s (%d bytes)...\n", otlp.Endpoint, len(body))
+	err = sendWithRetry(ctx, config.Retry, breakerFor(otlp.Endpoint), func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, otlp.Endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		for key, value := range otlp.Headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to send OTLP request: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return newAPIError(resp)
+		}
+		// Тело дочитывается только для возврата соединения в пул: ответ
+		// уже принят, ошибка чтения на результат не влияет
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logf("Metrics successfully exported to OTLP endpoint\n")
+	return nil
+}
+
+// Структуры OTLP/JSON (ExportMetricsServiceRequest). 64-битные числа
+// кодируются строками, как требует отображение protobuf в JSON.
+type OTLPMetrics struct {
+	ResourceMetrics []OTLPResourceMetrics `json:"resourceMetrics"`
+}
+
+type OTLPResourceMetrics struct {
+	Resource     OTLPResource       `json:"resource"`
+	ScopeMetrics []OTLPScopeMetrics `json:"scopeMetrics"`
+}
+
+type OTLPResource struct {
+	Attributes []OTLPKeyValue `json:"attributes"`
+}
+
+type OTLPScopeMetrics struct {
+	Scope   OTLPScope    `json:"scope"`
+	Metrics []OTLPMetric `json:"metrics"`
+}
+
+type OTLPScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type OTLPMetric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Unit        string     `json:"unit,omitempty"`
+	Gauge       *OTLPGauge `json:"gauge,omitempty"`
+	Sum         *OTLPSum   `json:"sum,omitempty"`
+}
+
+type OTLPGauge struct {
+	DataPoints []OTLPDataPoint `json:"dataPoints"`
+}
+
+// Временная агрегация суммы: накопительная с момента StartTimeUnixNano
+const otlpTemporalityCumulative = 2
+
+type OTLPSum struct {
+	DataPoints             []OTLPDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+}
+
+type OTLPDataPoint struct {
+	Attributes        []OTLPKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          *float64       `json:"asDouble,omitempty"`
+	AsInt             string         `json:"asInt,omitempty"`
+}
+
+type OTLPKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTLPAnyValue `json:"value"`
+}
+
+type OTLPAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    string  `json:"intValue,omitempty"`
+}
+
+// NewOTLPMetrics переводит отчет в метрики OpenTelemetry. Каждый хост
+// становится ресурсом с атрибутами host.name, os.type и os.description;
+// host.id не заполняется: в отчете нет машинного идентификатора, а
+// host_id - это имя хоста. Имена метрик и атрибутов следуют
+// семантическим соглашениям system.* и process.*.
+func NewOTLPMetrics(report *SystemReport, serviceName string) *OTLPMetrics {
+	result := &OTLPMetrics{ResourceMetrics: []OTLPResourceMetrics{}}
+	for _, r := range report.Reports {
+		h := newHostData(r)
+		b := otlpBuilder{metrics: make(map[string]*OTLPMetric)}
+		b.now = otlpTime(h.Timestamp)
+		if h.Host != nil && !h.Host.Uptime.BootTime.IsZero() {
+			b.start = otlpTime(h.Host.Uptime.BootTime)
+		}
+		b.addHost(h)
+
+		hostname := h.HostID
+		if h.Host != nil && h.Host.Hostname != "" {
+			hostname = h.Host.Hostname
+		}
+		resource := OTLPResource{Attributes: []OTLPKeyValue{otlpString("host.name", hostname)}}
+		if serviceName != "" {
+			resource.Attributes = append(resource.Attributes, otlpString("service.name", serviceName))
+		}
+		if h.Host != nil {
+			osType, _, _ := strings.Cut(h.Host.OS, " ")
+			resource.Attributes = append(resource.Attributes,
+				otlpString("os.type", strings.ToLower(osType)),
+				otlpString("os.description", h.Host.OS),
+				otlpString("os.version", h.Host.Kernel))
+		}
+
+		result.ResourceMetrics = append(result.ResourceMetrics, OTLPResourceMetrics{
+			Resource: resource,
+			ScopeMetrics: []OTLPScopeMetrics{{
+				Scope:   OTLPScope{Name: otlpScopeName, Version: report.APIVersion},
+				Metrics: b.list,
+			}},
+		})
+	}
+	return result
+}
+
+// otlpBuilder собирает точки по метрикам в порядке первого появления
+type otlpBuilder struct {
+	now, start string
+	metrics    map[string]*OTLPMetric
+	list       []OTLPMetric
+	order      []string
+}
+
+func (b *otlpBuilder) addHost(h hostData) {
+	if h.Host != nil {
+		b.gauge("system.uptime", "s", "Time since the host was booted.", float64(h.Host.Uptime.Hours*3600))
+	}
+
+	if h.CPU != nil {
+		b.sum("system.cpu.physical.count", "{cpu}", "Number of physical CPU cores.", false, float64(h.CPU.Cores))
+		b.sum("system.cpu.logical.count", "{cpu}", "Number of logical CPUs.", false, float64(h.CPU.Threads))
+		b.gauge("system.cpu.utilization", "1", "CPU usage from 0 to 1.", h.CPU.UsagePercent/100)
+		b.gauge("system.cpu.load_average.1m", "{thread}", "1-minute load average.", h.CPU.LoadAverage.Load1)
+		b.gauge("system.cpu.load_average.5m", "{thread}", "5-minute load average.", h.CPU.LoadAverage.Load5)
+		b.gauge("system.cpu.load_average.15m", "{thread}", "15-minute load average.", h.CPU.LoadAverage.Load15)
+	}
+
+	if h.Memory != nil {
+		ram, swap := h.Memory.RAM, h.Memory.Swap
+		b.sum("system.memory.limit", "By", "Total RAM.", false, ram.TotalGB*bytesPerGB)
+		for _, s := range []struct {
+			state string
+			value float64
+		}{
+			{"used", ram.UsedGB * bytesPerGB},
+			{"free", ram.FreeGB * bytesPerGB},
+			{"cached", ram.CachedGB * bytesPerGB},
+			{"buffers", ram.BuffersMB * 1024 * 1024},
+		} {
+			b.sum("system.memory.usage", "By", "RAM in use by state.", false, s.value, otlpString("system.memory.state", s.state))
+		}
+		b.gauge("system.memory.utilization", "1", "Used RAM from 0 to 1.", ram.UsedPercent/100, otlpString("system.memory.state", "used"))
+		b.sum("system.paging.usage", "By", "Swap space by state.", false, swap.UsedGB*bytesPerGB, otlpString("system.paging.state", "used"))
+		b.sum("system.paging.usage", "By", "Swap space by state.", false, (swap.TotalGB-swap.UsedGB)*bytesPerGB, otlpString("system.paging.state", "free"))
+	}
+
+	for _, d := range h.Disks {
+		attrs := []OTLPKeyValue{
+			otlpString("system.device", d.Device),
+			otlpString("system.filesystem.mountpoint", d.Mountpoint),
+			otlpString("system.filesystem.type", d.Filesystem),
+		}
+		b.sum("system.filesystem.usage", "By", "Filesystem space by state.", false, d.UsedGB*bytesPerGB,
+			append(attrs, otlpString("system.filesystem.state", "used"))...)
+		b.sum("system.filesystem.usage", "By", "Filesystem space by state.", false, d.FreeGB*bytesPerGB,
+			append(attrs, otlpString("system.filesystem.state", "free"))...)
+		b.gauge("system.filesystem.utilization", "1", "Used filesystem space from 0 to 1.", d.UsedPercent/100, attrs...)
+	}
+
+	if h.Network != nil {
+		for _, iface := range h.Network.Interfaces {
+			name := otlpString("network.interface.name", iface.Name)
+			b.sum("system.network.io", "By", "Bytes transmitted and received.", true, iface.Statistics.SentGB*bytesPerGB,
+				name, otlpString("network.io.direction", "transmit"))
+			b.sum("system.network.io", "By", "Bytes transmitted and received.", true, iface.Statistics.ReceivedGB*bytesPerGB,
+				name, otlpString("network.io.direction", "receive"))
+		}
+	}
+
+	for _, p := range h.Processes {
+		pid := OTLPKeyValue{Key: "process.pid", Value: OTLPAnyValue{IntValue: strconv.Itoa(int(p.PID))}}
+		name := otlpString("process.executable.name", p.Name)
+		b.sum("process.memory.usage", "By", "Resident memory of a top process.", false, p.MemoryMB*1024*1024, pid, name)
+		b.gauge("process.cpu.utilization", "1", "CPU usage of a top process from 0 to 1.", p.CPUPercent/100, pid, name)
+	}
+
+	for _, name := range b.order {
+		b.list = append(b.list, *b.metrics[name])
+	}
+}
+
+func (b *otlpBuilder) metric(name, unit, description string) *OTLPMetric {
+	m, ok := b.metrics[name]
+	if !ok {
+		m = &OTLPMetric{Name: name, Unit: unit, Description: description}
+		b.metrics[name] = m
+		b.order = append(b.order, name)
+	}
+	return m
+}
+
+func (b *otlpBuilder) gauge(name, unit, description string, value float64, attrs ...OTLPKeyValue) {
+	m := b.metric(name, unit, description)
+	if m.Gauge == nil {
+		m.Gauge = &OTLPGauge{}
+	}
+	m.Gauge.DataPoints = append(m.Gauge.DataPoints, b.point(value, attrs, ""))
+}
+
+// sum добавляет точку суммы: monotonic - счетчик, иначе UpDownCounter
+func (b *otlpBuilder) sum(name, unit, description string, monotonic bool, value float64, attrs ...OTLPKeyValue) {
+	m := b.metric(name, unit, description)
+	if m.Sum == nil {
+		m.Sum = &OTLPSum{AggregationTemporality: otlpTemporalityCumulative, IsMonotonic: monotonic}
+	}
+	m.Sum.DataPoints = append(m.Sum.DataPoints, b.point(value, attrs, b.start))
+}
+
+func (b *otlpBuilder) point(value float64, attrs []OTLPKeyValue, start string) OTLPDataPoint {
+	return OTLPDataPoint{
+		Attributes:        append([]OTLPKeyValue(nil), attrs...),
+		StartTimeUnixNano: start,
+		TimeUnixNano:      b.now,
+		AsDouble:          &value,
+	}
+}
+
+func otlpString(key, value string) OTLPKeyValue {
+	return OTLPKeyValue{Key: key, Value: OTLPAnyValue{StringValue: &value}}
+}
+
+func otlpTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,205 @@
+package reporter
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// otlpReceiver принимает OTLP/HTTP JSON и запоминает запросы
+type otlpReceiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []OTLPMetrics
+	statuses []int // коды ответов по порядку; после конца - 200
+}
+
+func (rcv *otlpReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = gz
+	}
+	var metrics OTLPMetrics
+	if err := json.NewDecoder(body).Decode(&metrics); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rcv.requests = append(rcv.requests, r)
+	rcv.bodies = append(rcv.bodies, metrics)
+
+	if n := len(rcv.requests); n <= len(rcv.statuses) {
+		w.WriteHeader(rcv.statuses[n-1])
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func otlpTestConfig(endpoint string) *Config {
+	config := DefaultConfig()
+	config.AgentName = "reporter-test"
+	config.OTLP.Enabled = true
+	config.OTLP.Endpoint = endpoint
+	config.OTLP.Headers = map[string]string{"Authorization": "Bearer secret", "X-Tenant": "ops"}
+	config.Retry = RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Multiplier: 2}
+	return config
+}
+
+func TestExportOTLP(t *testing.T) {
+	for _, compression := range []string{CompressionNone, CompressionGzip} {
+		t.Run(compression, func(t *testing.T) {
+			receiver := &otlpReceiver{}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			config := otlpTestConfig(server.URL + "/v1/metrics")
+			config.OTLP.Compression = compression
+			if err := ExportOTLP(context.Background(), config, sampleReport()); err != nil {
+				t.Fatalf("ExportOTLP: %v", err)
+			}
+			if len(receiver.requests) != 1 {
+				t.Fatalf("receiver got %d requests, want 1", len(receiver.requests))
+			}
+
+			req := receiver.requests[0]
+			if req.URL.Path != "/v1/metrics" || req.Method != http.MethodPost {
+				t.Errorf("request = %s %s, want POST /v1/metrics", req.Method, req.URL.Path)
+			}
+			if got := req.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := req.Header.Get("Content-Encoding"); (got == "gzip") != (compression == CompressionGzip) {
+				t.Errorf("Content-Encoding = %q with compression %q", got, compression)
+			}
+			for key, value := range config.OTLP.Headers {
+				if got := req.Header.Get(key); got != value {
+					t.Errorf("header %s = %q, want %q", key, got, value)
+				}
+			}
+
+			checkOTLPMetrics(t, &receiver.bodies[0])
+		})
+	}
+}
+
+func checkOTLPMetrics(t *testing.T, metrics *OTLPMetrics) {
+	t.Helper()
+	if len(metrics.ResourceMetrics) != 1 {
+		t.Fatalf("got %d resources, want 1", len(metrics.ResourceMetrics))
+	}
+	rm := metrics.ResourceMetrics[0]
+
+	attrs := make(map[string]string)
+	for _, kv := range rm.Resource.Attributes {
+		if kv.Value.StringValue != nil {
+			attrs[kv.Key] = *kv.Value.StringValue
+		}
+	}
+	wantAttrs := map[string]string{
+		"host.name":      "web1",
+		"service.name":   "reporter-test",
+		"os.type":        "debian",
+		"os.description": "debian 12.5",
+		"os.version":     "6.1.0-18-amd64",
+	}
+	for key, want := range wantAttrs {
+		if attrs[key] != want {
+			t.Errorf("resource attribute %s = %q, want %q", key, attrs[key], want)
+		}
+	}
+	if id, ok := attrs["host.id"]; ok {
+		t.Errorf("host.id = %q, want it unset without a machine id", id)
+	}
+
+	if len(rm.ScopeMetrics) != 1 || rm.ScopeMetrics[0].Scope.Name != otlpScopeName {
+		t.Fatalf("scope metrics = %+v", rm.ScopeMetrics)
+	}
+	byName := make(map[string]OTLPMetric)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		byName[m.Name] = m
+	}
+	wantUnits := map[string]string{
+		"system.uptime":                 "s",
+		"system.cpu.utilization":        "1",
+		"system.cpu.logical.count":      "{cpu}",
+		"system.memory.usage":           "By",
+		"system.filesystem.usage":       "By",
+		"system.filesystem.utilization": "1",
+		"system.network.io":             "By",
+		"process.memory.usage":          "By",
+	}
+	for name, unit := range wantUnits {
+		m, ok := byName[name]
+		if !ok {
+			t.Errorf("metric %s missing", name)
+			continue
+		}
+		if m.Unit != unit {
+			t.Errorf("metric %s unit = %q, want %q", name, m.Unit, unit)
+		}
+	}
+
+	if m := byName["system.cpu.utilization"]; m.Gauge == nil || len(m.Gauge.DataPoints) != 1 || *m.Gauge.DataPoints[0].AsDouble != 0.125 {
+		t.Errorf("system.cpu.utilization = %+v, want gauge 0.125", m.Gauge)
+	}
+	if m := byName["system.network.io"]; m.Sum == nil || !m.Sum.IsMonotonic || len(m.Sum.DataPoints) != 2 {
+		t.Errorf("system.network.io = %+v, want monotonic sum with 2 points", m.Sum)
+	}
+	if m := byName["system.filesystem.usage"]; m.Sum == nil || len(m.Sum.DataPoints) != 4 {
+		t.Errorf("system.filesystem.usage = %+v, want 4 points (2 disks x used/free)", m.Sum)
+	}
+}
+
+func TestOTLPHostNameWithoutHostSection(t *testing.T) {
+	report := sampleReport()
+	delete(report.Reports[0].Sections, "1")
+	attrs := NewOTLPMetrics(report, "").ResourceMetrics[0].Resource.Attributes
+	if len(attrs) != 1 || attrs[0].Key != "host.name" || *attrs[0].Value.StringValue != "web1" {
+		t.Errorf("resource attributes = %+v, want only host.name from host_id", attrs)
+	}
+}
+
+func TestExportOTLPErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		wantErr  string
+	}{
+		{"retried after 503", []int{http.StatusServiceUnavailable}, 2, ""},
+		{"400 not retried", []int{http.StatusBadRequest}, 1, "status: 400"},
+		{"gives up", []int{500, 500, 500}, 3, "giving up after 3 attempts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &otlpReceiver{statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			err := ExportOTLP(context.Background(), otlpTestConfig(server.URL+"/v1/metrics"), sampleReport())
+			if len(receiver.requests) != tt.requests {
+				t.Errorf("receiver got %d requests, want %d", len(receiver.requests), tt.requests)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
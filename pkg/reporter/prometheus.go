@@ -0,0 +1,236 @@
+package reporter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusContentType тип содержимого текстового формата Prometheus
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsPrefix префикс имен всех метрик
+const metricsPrefix = "reporter_"
+
+const bytesPerGB = 1024 * 1024 * 1024
+
+// WritePrometheus пишет отчет в текстовом формате Prometheus. Каждая
+// метрика имеет метку host (host_id); диски, интерфейсы и процессы
+// различаются собственными метками. Объемы переводятся в байты,
+// проценты - в доли единицы, как принято в Prometheus.
+func WritePrometheus(report *SystemReport, w io.Writer) error {
+	var p promWriter
+	for _, r := range report.Reports {
+		p.addHost(newHostData(r))
+	}
+	return p.write(w)
+}
+
+// addHost добавляет метрики одного хоста
+func (p *promWriter) addHost(h hostData) {
+	host := []string{"host", h.HostID}
+
+	if !h.Timestamp.IsZero() {
+		p.add("report_timestamp_seconds", "gauge", "Time the report was generated.",
+			float64(h.Timestamp.UnixNano())/1e9, host...)
+	}
+	for _, section := range h.Sections {
+		up := 1.0
+		if section.Status != "" {
+			up = 0
+		}
+		p.add("section_up", "gauge", "Whether the report section was collected successfully.",
+			up, append(host, "section", sectionName(section))...)
+	}
+
+	if h.Host != nil {
+		p.add("host_info", "gauge", "Host information, value is always 1.", 1,
+			append(host, "hostname", h.Host.Hostname, "os", h.Host.OS, "kernel", h.Host.Kernel)...)
+		if !h.Host.Uptime.BootTime.IsZero() {
+			p.add("host_boot_time_seconds", "gauge", "Host boot time.",
+				float64(h.Host.Uptime.BootTime.Unix()), host...)
+		}
+	}
+
+	if h.CPU != nil {
+		p.add("cpu_cores", "gauge", "Number of physical CPU cores.", float64(h.CPU.Cores), host...)
+		p.add("cpu_threads", "gauge", "Number of logical CPUs.", float64(h.CPU.Threads), host...)
+		p.add("cpu_usage_ratio", "gauge", "CPU usage from 0 to 1.", h.CPU.UsagePercent/100, host...)
+		p.add("load1", "gauge", "1-minute load average.", h.CPU.LoadAverage.Load1, host...)
+		p.add("load5", "gauge", "5-minute load average.", h.CPU.LoadAverage.Load5, host...)
+		p.add("load15", "gauge", "15-minute load average.", h.CPU.LoadAverage.Load15, host...)
+	}
+
+	if h.Memory != nil {
+		ram, swap := h.Memory.RAM, h.Memory.Swap
+		p.add("memory_total_bytes", "gauge", "Total RAM.", ram.TotalGB*bytesPerGB, host...)
+		p.add("memory_available_bytes", "gauge", "RAM available for new processes.", ram.AvailableGB*bytesPerGB, host...)
+		p.add("memory_used_bytes", "gauge", "Used RAM.", ram.UsedGB*bytesPerGB, host...)
+		p.add("memory_free_bytes", "gauge", "Free RAM.", ram.FreeGB*bytesPerGB, host...)
+		p.add("memory_cached_bytes", "gauge", "RAM used by the page cache.", ram.CachedGB*bytesPerGB, host...)
+		p.add("memory_buffers_bytes", "gauge", "RAM used by kernel buffers.", ram.BuffersMB*1024*1024, host...)
+		p.add("swap_total_bytes", "gauge", "Total swap space.", swap.TotalGB*bytesPerGB, host...)
+		p.add("swap_used_bytes", "gauge", "Used swap space.", swap.UsedGB*bytesPerGB, host...)
+	}
+
+	for _, d := range h.Disks {
+		labels := append(host, "device", d.Device, "mountpoint", d.Mountpoint, "fstype", d.Filesystem)
+		p.add("filesystem_size_bytes", "gauge", "Filesystem size.", d.TotalGB*bytesPerGB, labels...)
+		p.add("filesystem_used_bytes", "gauge", "Used filesystem space.", d.UsedGB*bytesPerGB, labels...)
+		p.add("filesystem_free_bytes", "gauge", "Free filesystem space.", d.FreeGB*bytesPerGB, labels...)
+	}
+
+	if h.Network != nil {
+		for _, iface := range h.Network.Interfaces {
+			labels := append(host, "interface", iface.Name)
+			p.add("network_transmit_bytes_total", "counter", "Bytes sent by the interface.",
+				iface.Statistics.SentGB*bytesPerGB, labels...)
+			p.add("network_receive_bytes_total", "counter", "Bytes received by the interface.",
+				iface.Statistics.ReceivedGB*bytesPerGB, labels...)
+		}
+	}
+
+	for _, proc := range h.Processes {
+		labels := append(host, "pid", strconv.Itoa(int(proc.PID)), "name", proc.Name)
+		p.add("process_resident_memory_bytes", "gauge", "Resident memory of a top process.",
+			proc.MemoryMB*1024*1024, labels...)
+		p.add("process_cpu_usage_ratio", "gauge", "CPU usage of a top process from 0 to 1.",
+			proc.CPUPercent/100, labels...)
+	}
+}
+
+// promWriter собирает сэмплы по семействам метрик, чтобы HELP и TYPE
+// каждого семейства выводились один раз, а сэмплы - вместе
+type promWriter struct {
+	families []*promFamily
+	index    map[string]*promFamily
+}
+
+type promFamily struct {
+	name    string
+	typ     string
+	help    string
+	samples []promSample
+}
+
+type promSample struct {
+	labels []string // Пары имя, значение
+	value  float64
+}
+
+func (p *promWriter) add(name, typ, help string, value float64, labels ...string) {
+	name = metricsPrefix + name
+	family, ok := p.index[name]
+	if !ok {
+		if p.index == nil {
+			p.index = make(map[string]*promFamily)
+		}
+		family = &promFamily{name: name, typ: typ, help: help}
+		p.index[name] = family
+		p.families = append(p.families, family)
+	}
+	family.samples = append(family.samples, promSample{labels: append([]string(nil), labels...), value: value})
+}
+
+func (p *promWriter) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, family := range p.families {
+		if _, err := bw.WriteString(family.text()); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// text возвращает семейство в текстовом формате: HELP, TYPE и сэмплы
+func (f *promFamily) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+	for _, sample := range f.samples {
+		b.WriteString(f.name)
+		if len(sample.labels) > 0 {
+			b.WriteByte('{')
+			for i := 0; i+1 < len(sample.labels); i += 2 {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, "%s=\"%s\"", sample.labels[i], escapeLabelValue(sample.labels[i+1]))
+			}
+			b.WriteByte('}')
+		}
+		b.WriteByte(' ')
+		b.WriteString(formatPromValue(sample.value))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
+
+func formatPromValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// MetricsHandler отдает последний собранный отчет в формате Prometheus.
+// Пока ни одного отчета не собрано, отвечает 503.
+func (r *Reporter) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.LastReport()
+		if report == nil {
+			http.Error(w, "no report collected yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", PrometheusContentType)
+		if err := WritePrometheus(report, w); err != nil {
+			logf("Warning: failed to write metrics: %v\n", err)
+		}
+	})
+}
+
+// ServeMetrics обслуживает /metrics на адресе addr до отмены ctx
+func (r *Reporter) ServeMetrics(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.MetricsHandler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	served := make(chan error, 1)
+	go func() { served <- server.ListenAndServe() }()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("metrics server: %v", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("metrics server shutdown: %v", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePrometheusGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePrometheus(sampleReport(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "prometheus", "sample.prom")
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("output differs from %s:\n%s", golden, buf.String())
+	}
+
+	// HELP и TYPE выводятся один раз на семейство, перед его сэмплами
+	seen := make(map[string]bool)
+	current := ""
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if name, ok := strings.CutPrefix(line, "# HELP "); ok {
+			name, _, _ = strings.Cut(name, " ")
+			if seen[name] {
+				t.Errorf("HELP for %s written twice", name)
+			}
+			seen[name], current = true, name
+			continue
+		}
+		if strings.HasPrefix(line, "# TYPE ") {
+			if !strings.HasPrefix(line, "# TYPE "+current+" ") {
+				t.Errorf("TYPE line %q does not follow HELP for %s", line, current)
+			}
+			continue
+		}
+		if name := strings.FieldsFunc(line, func(r rune) bool { return r == '{' || r == ' ' })[0]; name != current {
+			t.Errorf("sample %q outside its family %s", line, current)
+		}
+	}
+}
+
+func TestWritePrometheusEscaping(t *testing.T) {
+	report := sampleReport()
+	report.Reports[0].HostID = `web"1`
+	report.Reports[0].Sections["6"].Data.([]ProcessInfo)[0].Name = "a\\b\n\"c\""
+	report.Reports[0].Sections["3"] = Section{Name: SectionMemory, Title: "MEMORY INFORMATION", Status: SectionStatusTimeout, Error: "timed out"}
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(report, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	for _, want := range []string{
+		`reporter_cpu_cores{host="web\"1"} 4`,
+		`reporter_process_cpu_usage_ratio{host="web\"1",pid="1",name="a\\b\n\"c\""} 0.001`,
+		`reporter_section_up{host="web\"1",section="memory"} 0`,
+	} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("missing line %s", want)
+		}
+	}
+	if line, ok := lineWithPrefix(lines, "reporter_memory_total_bytes"); ok {
+		t.Errorf("metrics of failed section written: %s", line)
+	}
+}
+
+// failingWriter отказывает в записи после limit байт
+type failingWriter struct{ limit int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return w.limit, errors.New("connection reset")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestWritePrometheusWriteError(t *testing.T) {
+	if err := WritePrometheus(sampleReport(), &failingWriter{limit: 100}); err == nil || err.Error() != "connection reset" {
+		t.Errorf("WritePrometheus = %v, want the writer error", err)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	r := New(nil)
+	handler := r.MetricsHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status before first report = %d, want 503", rec.Code)
+	}
+
+	r.mu.Lock()
+	r.lastReport = sampleReport()
+	r.mu.Unlock()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != PrometheusContentType {
+		t.Errorf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), `reporter_cpu_cores{host="web1"} 4`) {
+		t.Errorf("body does not contain report metrics:\n%s", rec.Body.String())
+	}
+}
+
+// freeAddr возвращает свободный адрес на loopback
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// metricsStatus возвращает код ответа /metrics или 0, если адрес не отвечает
+func metricsStatus(addr string) int {
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://" + addr + "/metrics")
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode
+}
+
+// waitMetricsStatus ждет, пока /metrics на addr ответит кодом want
+func waitMetricsStatus(t *testing.T, addr string, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := metricsStatus(addr)
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("/metrics on %s = %d, want %d", addr, got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	discardLog(t)
+	addr := freeAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- New(nil).ServeMetrics(ctx, addr) }()
+
+	waitMetricsStatus(t, addr, http.StatusServiceUnavailable)
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("ServeMetrics after cancel = %v, want nil", err)
+	}
+	if status := metricsStatus(addr); status != 0 {
+		t.Errorf("server still answers %d after shutdown", status)
+	}
+
+	// Занятый адрес - ошибка запуска
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if err := New(nil).ServeMetrics(context.Background(), ln.Addr().String()); err == nil {
+		t.Error("ServeMetrics on a busy address returned nil")
+	}
+}
+
+func TestRunRestartsMetricsOnReload(t *testing.T) {
+	discardLog(t)
+	server, numbers := reportNumbers(t)
+	r := agentReporter(t, server.URL, time.Hour)
+	first, second := freeAddr(t), freeAddr(t)
+	config := *r.GetConfig()
+	config.MetricsListen = first
+	r.SetConfig(&config)
+
+	stop := startAgent(t, r)
+	<-numbers
+	waitMetricsStatus(t, first, http.StatusOK)
+
+	moved := config
+	moved.MetricsListen = second
+	r.SetConfig(&moved)
+	waitMetricsStatus(t, second, http.StatusOK)
+	waitMetricsStatus(t, first, 0)
+
+	disabled := config
+	disabled.MetricsListen = ""
+	r.SetConfig(&disabled)
+	waitMetricsStatus(t, second, 0)
+
+	if err := stop(); err != nil {
+		t.Errorf("Run = %v, want nil", err)
+	}
+}
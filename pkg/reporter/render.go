@@ -0,0 +1,448 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Пороги подсветки по умолчанию, в процентах
+const (
+	DefaultWarnPercent     = 80.0
+	DefaultCriticalPercent = 90.0
+)
+
+// TextOptions параметры текстового представления отчета
+type TextOptions struct {
+	// Color включает ANSI-подсветку заголовков, ошибок секций и значений
+	// выше порогов
+	Color bool
+	// Пороги загрузки для процентов CPU, памяти и дисков; 0 - значения
+	// по умолчанию
+	WarnPercent     float64
+	CriticalPercent float64
+}
+
+// RenderText выводит отчет в читаемом виде: секции с заголовками,
+// таблицы дисков, интерфейсов, процессов и контейнеров, объемы в
+// двоичных единицах. Подсветка включается, если w - терминал и не
+// задана переменная NO_COLOR (см. ColorEnabled).
+func RenderText(report *SystemReport, w io.Writer) error {
+	return RenderTextOptions(report, w, TextOptions{Color: ColorEnabled(w)})
+}
+
+// RenderTextOptions выводит отчет как RenderText с явными параметрами
+func RenderTextOptions(report *SystemReport, w io.Writer, opts TextOptions) error {
+	if opts.WarnPercent <= 0 {
+		opts.WarnPercent = DefaultWarnPercent
+	}
+	if opts.CriticalPercent <= 0 {
+		opts.CriticalPercent = DefaultCriticalPercent
+	}
+	t := &textRenderer{w: bufio.NewWriter(w), opts: opts}
+	for i, r := range report.Reports {
+		if i > 0 {
+			t.w.WriteString("\n")
+		}
+		t.renderHost(r)
+	}
+	return t.w.Flush()
+}
+
+// ColorEnabled сообщает, стоит ли подсвечивать вывод в w: w - терминал,
+// переменная NO_COLOR не задана и TERM не равен dumb
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiGreen  = "\x1b[32m"
+)
+
+type textRenderer struct {
+	w    *bufio.Writer
+	opts TextOptions
+}
+
+// paint оборачивает s в ANSI-код, если подсветка включена
+func (t *textRenderer) paint(code, s string) string {
+	if !t.opts.Color || code == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// levelColor возвращает цвет процента загрузки по порогам
+func (t *textRenderer) levelColor(percent float64) string {
+	switch {
+	case percent >= t.opts.CriticalPercent:
+		return ansiRed
+	case percent >= t.opts.WarnPercent:
+		return ansiYellow
+	}
+	return ""
+}
+
+func (t *textRenderer) percent(value float64) string {
+	return t.paint(t.levelColor(value), formatPercent(value))
+}
+
+func (t *textRenderer) renderHost(r Report) {
+	h := newHostData(r)
+	title := "Report for " + h.HostID
+	if r.ReportNumber > 0 {
+		title += fmt.Sprintf(" #%d", r.ReportNumber)
+	}
+	if !h.Timestamp.IsZero() {
+		title += ", " + h.Timestamp.Format("2006-01-02 15:04:05 MST")
+	}
+	t.w.WriteString(t.paint(ansiBold, title) + "\n")
+
+	for _, section := range h.Sections {
+		t.w.WriteString("\n" + t.paint(ansiBold, section.Title) + "\n")
+		if section.Status != "" {
+			t.w.WriteString("  " + t.paint(ansiRed, section.Status) + ": " + section.Error + "\n")
+			continue
+		}
+
+		switch sectionName(section) {
+		case SectionHost:
+			t.renderHostInfo(h.Host)
+		case SectionCPU:
+			t.renderCPU(h.CPU)
+		case SectionMemory:
+			t.renderMemory(h.Memory)
+		case SectionDisk:
+			t.renderDisks(h.Disks)
+		case SectionNetwork:
+			t.renderNetwork(h.Network)
+		case SectionProcesses:
+			t.renderProcesses(h.Processes)
+		case SectionDocker:
+			t.renderDocker(h.Docker)
+		case SectionSecurity:
+			t.renderSecurity(h.Security)
+		default:
+			t.renderRaw(section.Data)
+		}
+	}
+}
+
+// fields выводит пары имя, значение с выравниванием значений
+func (t *textRenderer) fields(pairs ...string) {
+	width := 0
+	for i := 0; i+1 < len(pairs); i += 2 {
+		width = max(width, utf8.RuneCountInString(pairs[i]))
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		t.w.WriteString("  " + pad(pairs[i]+":", width+1, false) + " " + pairs[i+1] + "\n")
+	}
+}
+
+func (t *textRenderer) renderHostInfo(info *HostInfo) {
+	if info == nil {
+		t.fields("Data", "none")
+		return
+	}
+	uptime := formatUptime(info.Uptime.Hours)
+	if !info.Uptime.BootTime.IsZero() {
+		uptime += " (since " + info.Uptime.BootTime.Format("2006-01-02 15:04") + ")"
+	}
+	t.fields("Hostname", info.Hostname, "OS", info.OS, "Kernel", info.Kernel, "Uptime", uptime)
+}
+
+func (t *textRenderer) renderCPU(info *CPUInfo) {
+	if info == nil {
+		t.fields("Data", "none")
+		return
+	}
+	load := info.LoadAverage
+	t.fields(
+		"Model", info.Model,
+		"Cores", fmt.Sprintf("%d (%d threads)", info.Cores, info.Threads),
+		"Usage", t.percent(info.UsagePercent),
+		"Load average", fmt.Sprintf("%.2f %.2f %.2f", load.Load1, load.Load5, load.Load15),
+	)
+}
+
+func (t *textRenderer) renderMemory(info *MemoryInfo) {
+	if info == nil {
+		t.fields("Data", "none")
+		return
+	}
+	ram, swap := info.RAM, info.Swap
+	t.fields(
+		"RAM", fmt.Sprintf("%s / %s (%s)", formatGB(ram.UsedGB), formatGB(ram.TotalGB), t.percent(ram.UsedPercent)),
+		"Available", formatGB(ram.AvailableGB),
+		"Free", formatGB(ram.FreeGB),
+		"Cached", formatGB(ram.CachedGB),
+		"Buffers", formatBytes(ram.BuffersMB*1024*1024),
+		"Swap", fmt.Sprintf("%s / %s (%s)", formatGB(swap.UsedGB), formatGB(swap.TotalGB), t.percent(swap.UsedPercent)),
+	)
+}
+
+func (t *textRenderer) renderDisks(disks []DiskInfo) {
+	table := newTextTable("DEVICE", "MOUNTPOINT", "FS", ">SIZE", ">USED", ">FREE", ">USE%")
+	for _, d := range disks {
+		table.row(
+			textCell{text: d.Device},
+			textCell{text: d.Mountpoint},
+			textCell{text: d.Filesystem},
+			textCell{text: formatGB(d.TotalGB)},
+			textCell{text: formatGB(d.UsedGB)},
+			textCell{text: formatGB(d.FreeGB)},
+			textCell{text: formatPercent(d.UsedPercent), color: t.levelColor(d.UsedPercent)},
+		)
+	}
+	t.table(table)
+}
+
+func (t *textRenderer) renderNetwork(info *NetworkInfo) {
+	if info == nil {
+		t.fields("Data", "none")
+		return
+	}
+	table := newTextTable("INTERFACE", "MAC", "ADDRESSES", ">SENT", ">RECEIVED")
+	for _, iface := range info.Interfaces {
+		mac := iface.MAC
+		if mac == "" {
+			mac = "-"
+		}
+		addrs := strings.Join(iface.IPs, ", ")
+		if addrs == "" {
+			addrs = "-"
+		}
+		table.row(
+			textCell{text: iface.Name},
+			textCell{text: mac},
+			textCell{text: addrs},
+			textCell{text: formatGB(iface.Statistics.SentGB)},
+			textCell{text: formatGB(iface.Statistics.ReceivedGB)},
+		)
+	}
+	t.table(table)
+}
+
+func (t *textRenderer) renderProcesses(processes []ProcessInfo) {
+	table := newTextTable(">PID", "NAME", ">MEMORY", ">CPU%")
+	for _, p := range processes {
+		table.row(
+			textCell{text: strconv.Itoa(int(p.PID))},
+			textCell{text: p.Name},
+			textCell{text: formatBytes(p.MemoryMB * 1024 * 1024)},
+			textCell{text: formatPercent(p.CPUPercent), color: t.levelColor(p.CPUPercent)},
+		)
+	}
+	t.table(table)
+}
+
+func (t *textRenderer) renderDocker(containers []DockerContainer) {
+	if len(containers) == 0 {
+		t.w.WriteString("  No running containers\n")
+		return
+	}
+	table := newTextTable("CONTAINER ID", "NAME", "IMAGE", "STATUS", "UPTIME")
+	for _, c := range containers {
+		color := ""
+		if c.Status != "running" {
+			color = ansiYellow
+		}
+		table.row(
+			textCell{text: c.ContainerID},
+			textCell{text: c.Name},
+			textCell{text: c.Image},
+			textCell{text: c.Status, color: color},
+			textCell{text: c.Uptime},
+		)
+	}
+	t.table(table)
+}
+
+func (t *textRenderer) renderSecurity(status *SecurityStatus) {
+	if status == nil {
+		t.fields("Data", "none")
+		return
+	}
+	fail2ban := t.paint(serviceColor(status.Fail2ban), status.Fail2ban)
+	var jails []string
+	for _, jail := range status.Fail2banJails {
+		jails = append(jails, fmt.Sprintf("%s: %d banned", jail.Name, jail.Banned))
+	}
+	if len(jails) > 0 {
+		fail2ban += " (" + strings.Join(jails, ", ") + ")"
+	}
+	ufw := t.paint(serviceColor(status.UfwStatus), status.UfwStatus)
+	if status.UfwStatus == SecurityActive {
+		ufw += fmt.Sprintf(" (%d rules)", status.UfwRules)
+	}
+
+	updates := status.LastUpdates
+	if status.DaysSinceUpgrade != nil {
+		updates += fmt.Sprintf(" (%d days ago, %d packages)", *status.DaysSinceUpgrade, status.PackagesUpgraded)
+	}
+	ssh := strconv.Itoa(status.SSHFailedAttempts)
+	if status.SSHWindowHours > 0 {
+		ssh += fmt.Sprintf(" in the last %gh", status.SSHWindowHours)
+	}
+	t.fields("Fail2ban", fail2ban, "UFW", ufw, "Last upgrade", updates, "SSH failed logins", ssh)
+}
+
+// renderRaw выводит данные секций сторонних сборщиков как JSON
+func (t *textRenderer) renderRaw(data interface{}) {
+	raw, err := json.MarshalIndent(data, "  ", "  ")
+	if err != nil {
+		t.w.WriteString("  " + err.Error() + "\n")
+		return
+	}
+	t.w.WriteString("  " + string(raw) + "\n")
+}
+
+func (t *textRenderer) table(table *textTable) {
+	if len(table.rows) == 0 {
+		t.w.WriteString("  No data\n")
+		return
+	}
+	table.write(t.w, t.paint)
+}
+
+// serviceColor цвет состояния службы безопасности
+func serviceColor(status string) string {
+	switch status {
+	case SecurityActive:
+		return ansiGreen
+	case SecurityInactive, SecurityNotInstalled:
+		return ansiYellow
+	}
+	return ""
+}
+
+// textTable таблица с выравниванием по ширине содержимого. Ширина
+// считается без ANSI-кодов, поэтому подсветка не сбивает колонки.
+type textTable struct {
+	header []string
+	right  []bool // Выравнивание колонки по правому краю
+	rows   [][]textCell
+}
+
+type textCell struct {
+	text  string
+	color string
+}
+
+// newTextTable создает таблицу; заголовок с префиксом ">" означает
+// колонку с выравниванием по правому краю
+func newTextTable(header ...string) *textTable {
+	table := &textTable{}
+	for _, h := range header {
+		right := strings.HasPrefix(h, ">")
+		table.header = append(table.header, strings.TrimPrefix(h, ">"))
+		table.right = append(table.right, right)
+	}
+	return table
+}
+
+func (tt *textTable) row(cells ...textCell) {
+	tt.rows = append(tt.rows, cells)
+}
+
+func (tt *textTable) write(w *bufio.Writer, paint func(code, s string) string) {
+	widths := make([]int, len(tt.header))
+	for i, h := range tt.header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range tt.rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell.text))
+		}
+	}
+
+	line := func(cells []textCell, code string) {
+		var b strings.Builder
+		b.WriteString("  ")
+		for i, cell := range cells {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			// Последнюю колонку с левым выравниванием не дополняем пробелами
+			text := cell.text
+			if tt.right[i] || i < len(cells)-1 {
+				text = pad(text, widths[i], tt.right[i])
+			}
+			color := cell.color
+			if code != "" {
+				color = code
+			}
+			b.WriteString(paint(color, text))
+		}
+		w.WriteString(b.String() + "\n")
+	}
+
+	header := make([]textCell, len(tt.header))
+	for i, h := range tt.header {
+		header[i] = textCell{text: h}
+	}
+	line(header, ansiBold)
+	for _, row := range tt.rows {
+		line(row, "")
+	}
+}
+
+// pad дополняет s пробелами до width символов
+func pad(s string, width int, right bool) string {
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", n) + s
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+// formatBytes переводит байты в двоичные единицы: 512 B, 1.5 KiB, 3.2 GiB
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for bytes >= 1024 && i < len(units)-1 {
+		bytes /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f B", bytes)
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
+
+// formatGB переводит значение в гигабайтах, как их хранит отчет
+func formatGB(gb float64) string {
+	return formatBytes(gb * bytesPerGB)
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64) + "%"
+}
+
+// formatUptime переводит часы работы в дни и часы: 3d 4h
+func formatUptime(hours uint64) string {
+	if hours < 24 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dd %dh", hours/24, hours%24)
+}
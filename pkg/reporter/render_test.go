@@ -0,0 +1,97 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// failedSectionsReport возвращает sampleReport с секцией памяти, не
+// уложившейся в срок, и секцией безопасности, завершившейся ошибкой
+func failedSectionsReport() *SystemReport {
+	report := sampleReport()
+	report.Reports[0].Sections["3"] = Section{Name: SectionMemory, Title: "MEMORY INFORMATION",
+		Status: SectionStatusTimeout, Error: "collector timed out after 15s"}
+	report.Reports[0].Sections["8"] = Section{Name: SectionSecurity, Title: "SECURITY STATUS",
+		Status: SectionStatusError, Error: "permission <denied>"}
+	return report
+}
+
+func TestRenderText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderTextOptions(failedSectionsReport(), &buf, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "\x1b[") {
+		t.Error("output contains ANSI codes with Color disabled")
+	}
+
+	lines := strings.Split(out, "\n")
+	for _, want := range []string{
+		"Report for web1 #1, 2024-03-10 12:00:00 UTC",
+		"  Kernel:   6.1.0-18-amd64",
+		"  Uptime:   10d 0h (since 2024-02-29 12:00)",
+		"  Model:        Intel(R) Xeon(R) <Gold> & Co",
+		"  Cores:        4 (8 threads)",
+		"  /dev/sdb1  /var/lib/data store  xfs   500.0 GiB  450.0 GiB  50.0 GiB  90.0%",
+		"  eth0       52:54:00:12:34:56  192.0.2.10/24  1.5 GiB   3.2 GiB",
+		"  812  nginx: worker  256.0 MiB  4.5%",
+		"  timeout: collector timed out after 15s",
+		"  error: permission <denied>",
+	} {
+		if !containsLine(lines, want) {
+			t.Errorf("missing line %q in:\n%s", want, out)
+		}
+	}
+
+	// Секции идут в порядке ключей, у неудавшейся - только статус
+	memory := strings.Index(out, "MEMORY INFORMATION")
+	if disk := strings.Index(out, "DISK INFORMATION"); memory < 0 || disk < memory {
+		t.Error("sections are out of order")
+	} else if strings.Contains(out[memory:disk], "GiB") {
+		t.Errorf("failed memory section rendered data: %q", out[memory:disk])
+	}
+}
+
+func TestRenderTextColor(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderTextOptions(failedSectionsReport(), &buf, TextOptions{Color: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		ansiBold + "HOST INFORMATION" + ansiReset,
+		ansiRed + "timeout" + ansiReset + ": collector timed out after 15s",
+		ansiRed + "90.0%" + ansiReset,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	// Загрузка ниже порога предупреждения не окрашивается
+	if strings.Contains(out, "40.0%"+ansiReset) {
+		t.Error("disk usage below the warning threshold is colored")
+	}
+
+	// Пороги из TextOptions заменяют значения по умолчанию
+	buf.Reset()
+	if err := RenderTextOptions(sampleReport(), &buf, TextOptions{Color: true, WarnPercent: 30, CriticalPercent: 95}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{ansiYellow + "40.0%" + ansiReset, ansiYellow + "90.0%" + ansiReset} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output with custom thresholds does not contain %q", want)
+		}
+	}
+}
+
+// containsLine сообщает, есть ли среди строк строка без учета хвостовых пробелов
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if strings.TrimRight(line, " ") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package reporter
+
+import "time"
+
+// sampleReport возвращает отчет одного хоста со встроенными секциями,
+// как после сбора; тесты меняют в нем отдельные поля
+func sampleReport() *SystemReport {
+	generated := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	section := func(name, title string, data interface{}) Section {
+		return Section{Name: name, Title: title, Data: data}
+	}
+	return &SystemReport{
+		APIVersion: APIVersion,
+		Generated:  generated,
+		Reports: []Report{{
+			HostID:       "web1",
+			ReportNumber: 1,
+			Timestamp:    generated,
+			Sections: map[string]Section{
+				"1": section(SectionHost, "HOST INFORMATION", &HostInfo{
+					Hostname: "web1",
+					OS:       "debian 12.5",
+					Kernel:   "6.1.0-18-amd64",
+					Uptime:   UptimeInfo{Hours: 240, BootTime: generated.Add(-240 * time.Hour)},
+				}),
+				"2": section(SectionCPU, "CPU INFORMATION", &CPUInfo{
+					Model:        "Intel(R) Xeon(R) <Gold> & Co",
+					Cores:        4,
+					Threads:      8,
+					UsagePercent: 12.5,
+					LoadAverage:  LoadAvg{Load1: 0.5, Load5: 0.4, Load15: 0.3},
+				}),
+				"3": section(SectionMemory, "MEMORY INFORMATION", &MemoryInfo{
+					RAM:  RAMInfo{TotalGB: 16, AvailableGB: 10, UsedGB: 6, UsedPercent: 37.5, FreeGB: 8, CachedGB: 2, BuffersMB: 128},
+					Swap: SwapInfo{TotalGB: 2, UsedGB: 0.5, UsedPercent: 25},
+				}),
+				"4": section(SectionDisk, "DISK INFORMATION", []DiskInfo{
+					{Device: "/dev/sda1", Mountpoint: "/", Filesystem: "ext4", TotalGB: 100, UsedGB: 40, UsedPercent: 40, FreeGB: 60},
+					{Device: "/dev/sdb1", Mountpoint: "/var/lib/data store", Filesystem: "xfs", TotalGB: 500, UsedGB: 450, UsedPercent: 90, FreeGB: 50},
+				}),
+				"5": section(SectionNetwork, "NETWORK INFORMATION", &NetworkInfo{Interfaces: []InterfaceInfo{
+					{Name: "eth0", MAC: "52:54:00:12:34:56", IPs: []string{"192.0.2.10/24"}, Statistics: InterfaceStats{SentGB: 1.5, ReceivedGB: 3.25}},
+				}}),
+				"6": section(SectionProcesses, "TOP PROCESSES", []ProcessInfo{
+					{PID: 1, Name: "systemd", MemoryMB: 12, CPUPercent: 0.1},
+					{PID: 812, Name: "nginx: worker", MemoryMB: 256, CPUPercent: 4.5},
+				}),
+			},
+		}},
+	}
+}
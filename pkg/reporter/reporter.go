@@ -1,8 +1,33 @@
 package reporter
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// LogOutput получает сообщения пакета о ходе работы и предупреждения.
+// Программа, которая пишет отчет в stdout, переключает его на os.Stderr,
+// чтобы сообщения не смешивались с отчетом.
+var LogOutput io.Writer = os.Stdout
+
+// logf пишет сообщение о ходе работы в LogOutput
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(LogOutput, format, args...)
+}
+
 // Reporter основной тип для работы с системными отчетами
 type Reporter struct {
-	config *Config
+	mu           sync.Mutex
+	config       *Config
+	collectors   []Collector
+	builtins     map[string]Collector
+	disabled     map[string]bool
+	reportNumber int
+	lastReport   *SystemReport
+	reloaded     chan struct{} // Сигнал Run о замене конфигурации (SetConfig)
 }
 
 // New создает новый экземпляр Reporter
@@ -10,17 +35,40 @@ func New(config *Config) *Reporter {
 	if config == nil {
 		config = DefaultConfig()
 	}
-	return &Reporter{config: config}
+	r := &Reporter{
+		config:     config,
+		collectors: builtinCollectors(config),
+		builtins:   make(map[string]Collector),
+		disabled:   make(map[string]bool),
+		reloaded:   make(chan struct{}, 1),
+	}
+	for _, c := range r.collectors {
+		r.builtins[c.Name()] = c
+	}
+	return r
 }
 
 // GenerateAndSend генерирует и отправляет отчет
 func (r *Reporter) GenerateAndSend() error {
+	return r.GenerateAndSendContext(context.Background())
+}
+
+// GenerateAndSendContext генерирует и отправляет отчет с учетом отмены ctx
+func (r *Reporter) GenerateAndSendContext(ctx context.Context) error {
 	// Генерируем отчет
-	report, err := GenerateSystemReport()
+	report, err := r.GenerateReportContext(ctx)
 	if err != nil {
 		return fmt.Errorf("error generating report: %v", err)
 	}
 
+	// Доставляем на API и в дополнительные цели из конфигурации
+	return NewPipeline(r, r.Sinks()...).Dispatch(ctx, report)
+}
+
+// Send отправляет уже собранный отчет на API: через очередь неотправленных
+// отчетов, повторы и, если включено, дельта-отправку. Позволяет сохранить,
+// захешировать и отправить один и тот же отчет (см. Pipeline).
+func (r *Reporter) Send(ctx context.Context, report *SystemReport) error {
 	// Конвертируем для API
 	reportData, err := ConvertToMap(report)
 	if err != nil {
@@ -28,19 +76,132 @@ func (r *Reporter) GenerateAndSend() error {
 	}
 
 	// Отправляем на API
-	if err := SendReportToAPI(r.config, reportData); err != nil {
-		return fmt.Errorf("error sending report to API: %v", err)
+	config := r.GetConfig()
+	request := &APIReportRequest{
+		Agent:  config.AgentName,
+		Report: reportData,
+	}
+
+	var plan *deltaPlan
+	if config.Delta {
+		plan, err = planDelta(config, report, request)
+		if err != nil {
+			logf("Warning: delta reporting disabled for this run: %v\n", err)
+		}
+	}
+
+	if err := deliver(ctx, config, request, plan); err != nil {
+		return fmt.Errorf("error sending report to API: %w", err)
 	}
 
 	return nil
 }
 
+// deliver отправляет запрос, предварительно дослав очередь неотправленных
+// отчетов. Если отправка не удалась, запрос ставится в очередь, чтобы на
+// сервере не осталось пропусков в истории. При дельта-отправке (plan != nil)
+// в сеть уходят только изменившиеся секции, а в очередь - всегда полный отчет.
+func deliver(ctx context.Context, config *Config, request *APIReportRequest, plan *deltaPlan) error {
+	var spool *Spool
+	if config.SpoolDir != "" {
+		var err error
+		spool, err = NewSpool(config.SpoolDir, config.SpoolMaxBytes, config.SpoolMaxAge)
+		if err != nil {
+			logf("Warning: spool unavailable, sending directly: %v\n", err)
+		}
+	}
+
+	if spool != nil {
+		sent, err := spool.Drain(ctx, func(ctx context.Context, queued *APIReportRequest) error {
+			_, err := sendRequest(ctx, config, queued)
+			return err
+		})
+		if sent > 0 {
+			logf("Sent %d spooled report(s)\n", sent)
+			// Сервер получил более старые отчеты, базовый хеш дельты устарел
+			plan.forceFull()
+		}
+		if err != nil {
+			return spoolRequest(spool, request, err)
+		}
+	}
+
+	toSend := plan.requestFor(request)
+	result, err := sendRequest(ctx, config, toSend)
+	if err != nil && toSend != request && isResyncRequest(err) {
+		logf("Server requested full resync, sending full report...\n")
+		toSend = request
+		result, err = sendRequest(ctx, config, request)
+	}
+	if err == nil {
+		plan.commit(toSend == request, result.resync)
+		return nil
+	}
+
+	if spool == nil || IsPermanent(err) {
+		return err
+	}
+	return spoolRequest(spool, request, err)
+}
+
+// spoolRequest ставит неотправленный запрос в очередь
+func spoolRequest(spool *Spool, request *APIReportRequest, sendErr error) error {
+	if err := spool.Put(request); err != nil {
+		return fmt.Errorf("%v; failed to spool report: %v", sendErr, err)
+	}
+	pending, _ := spool.Len()
+	return fmt.Errorf("%v; report queued in %s (%d pending)", sendErr, spool.Dir(), pending)
+}
+
 // GenerateReport генерирует отчет без отправки
 func (r *Reporter) GenerateReport() (*SystemReport, error) {
-	return GenerateSystemReport()
+	return r.GenerateReportContext(context.Background())
+}
+
+// GenerateReportContext генерирует отчет без отправки с учетом отмены ctx
+func (r *Reporter) GenerateReportContext(ctx context.Context) (*SystemReport, error) {
+	report, err := generateReport(ctx, r.Collectors(), newCollectOptions(r.GetConfig()))
+	if err != nil {
+		return nil, err
+	}
+
+	// Номер отчета растет от цикла к циклу в пределах жизни Reporter
+	r.mu.Lock()
+	r.reportNumber++
+	report.Reports[0].ReportNumber = r.reportNumber
+	r.lastReport = report
+	r.mu.Unlock()
+
+	return report, nil
+}
+
+// LastReport возвращает последний собранный отчет или nil
+func (r *Reporter) LastReport() *SystemReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastReport
 }
 
 // GetConfig возвращает конфигурацию репортера
 func (r *Reporter) GetConfig() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.config
 }
+
+// SetConfig заменяет конфигурацию; встроенные сборщики пересоздаются под
+// новые настройки. Безопасно вызывать во время работы Run: сервер метрик
+// перезапускается сразу, интервал применяется со следующего цикла.
+func (r *Reporter) SetConfig(config *Config) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config = config
+	r.resetBuiltins(config)
+	select {
+	case r.reloaded <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,246 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen возвращается без обращения к серверу, пока автомат
+// размыкания открыт после серии неудачных отправок
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// RetryConfig настройки повторов отправки и автомата размыкания
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts" toml:"max_attempts"`       // Всего попыток на отчет, 1 - без повторов
+	InitialBackoff time.Duration `yaml:"initial_backoff" toml:"initial_backoff"` // Пауза перед первым повтором
+	MaxBackoff     time.Duration `yaml:"max_backoff" toml:"max_backoff"`         // Предел паузы, в том числе по Retry-After
+	Multiplier     float64       `yaml:"multiplier" toml:"multiplier"`           // Множитель паузы между повторами
+
+	BreakerThreshold int           `yaml:"breaker_threshold" toml:"breaker_threshold"` // Неудачных попыток подряд до размыкания, 0 - выключен
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown" toml:"breaker_cooldown"`   // Время в разомкнутом состоянии до пробной попытки
+}
+
+// DefaultRetryConfig возвращает настройки повторов по умолчанию
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:      4,
+		InitialBackoff:   time.Second,
+		MaxBackoff:       30 * time.Second,
+		Multiplier:       2,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	}
+}
+
+// APIError ответ API с кодом, отличным от 200/204
+type APIError struct {
+	StatusCode int
+	RetryAfter time.Duration // Значение заголовка Retry-After, если он был
+	Body       string        // Начало тела ответа для диагностики
+}
+
+func (e *APIError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("API returned status: %d: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("API returned status: %d", e.StatusCode)
+}
+
+// Retryable сообщает, имеет ли смысл повторить запрос: повторяются
+// 408, 429 и 5xx, остальные 4xx означают ошибку в самом запросе
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= 500
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return apiErr
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в виде HTTP-даты
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// IsPermanent сообщает, что сервер отверг отчет и повтор не поможет
+func IsPermanent(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && !apiErr.Retryable()
+}
+
+// sendWithRetry вызывает send с экспоненциальной паузой и случайным
+// разбросом между попытками. Ответы 429/503 с Retry-After задают паузу
+// сами; если сервер просит ждать дольше MaxBackoff, повторы прекращаются.
+func sendWithRetry(ctx context.Context, config RetryConfig, breaker *circuitBreaker, send func() error) error {
+	attempts := max(config.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = breaker.allow(config); err != nil {
+			return err
+		}
+
+		err = send()
+		if err == nil {
+			breaker.success()
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			breaker.abort()
+			return err
+		}
+		if IsPermanent(err) {
+			// Сервер ответил осмысленно, значит он работает
+			breaker.success()
+			return err
+		}
+		breaker.failure(config)
+
+		if attempt == attempts {
+			break
+		}
+		delay := backoffDelay(config, attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			if config.MaxBackoff > 0 && apiErr.RetryAfter > config.MaxBackoff {
+				return fmt.Errorf("%v (server asked to retry after %s)", err, apiErr.RetryAfter)
+			}
+			delay = apiErr.RetryAfter
+		}
+
+		logf("Send attempt %d/%d failed: %v; retrying in %s\n", attempt, attempts, err, delay.Round(time.Millisecond))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+}
+
+// backoffDelay возвращает паузу перед повтором номер attempt ("full jitter")
+func backoffDelay(config RetryConfig, attempt int) time.Duration {
+	delay := float64(config.InitialBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	multiplier := config.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+	}
+	if config.MaxBackoff > 0 && delay > float64(config.MaxBackoff) {
+		delay = float64(config.MaxBackoff)
+	}
+	return time.Duration(rand.Int64N(int64(delay)) + 1)
+}
+
+// circuitBreaker размыкается после BreakerThreshold неудачных попыток
+// подряд и не пускает запросы BreakerCooldown. Затем пропускает одну
+// пробную попытку: успех замыкает автомат, неудача снова размыкает.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[string]*circuitBreaker)
+)
+
+// breakerFor возвращает общий для процесса автомат для адреса API
+func breakerFor(apiURL string) *circuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[apiURL]
+	if !ok {
+		b = &circuitBreaker{}
+		breakers[apiURL] = b
+	}
+	return b
+}
+
+func (b *circuitBreaker) allow(config RetryConfig) error {
+	if config.BreakerThreshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(b.openUntil) || b.probing {
+		return fmt.Errorf("%w: retry after %s", ErrCircuitOpen, b.openUntil.Sub(now).Round(time.Second))
+	}
+	b.probing = true
+	return nil
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+	b.probing = false
+}
+
+// abort снимает пробную попытку, прерванную отменой контекста
+func (b *circuitBreaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure(config RetryConfig) {
+	if config.BreakerThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.probing || b.failures >= config.BreakerThreshold {
+		cooldown := config.BreakerCooldown
+		if cooldown <= 0 {
+			cooldown = DefaultRetryConfig().BreakerCooldown
+		}
+		b.openUntil = time.Now().Add(cooldown)
+		b.probing = false
+		logf("Warning: circuit breaker opened for %s after %d failed attempts\n", cooldown, b.failures)
+	}
+}
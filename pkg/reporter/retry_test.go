@@ -0,0 +1,187 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	config := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	tests := []struct {
+		name    string
+		config  RetryConfig
+		attempt int
+		max     time.Duration
+	}{
+		{"first retry", config, 1, 100 * time.Millisecond},
+		{"grows by multiplier", config, 3, 400 * time.Millisecond},
+		{"capped by max backoff", config, 10, time.Second},
+		{"multiplier below one", RetryConfig{InitialBackoff: 100 * time.Millisecond, Multiplier: 0.5}, 5, 100 * time.Millisecond},
+		{"no backoff", RetryConfig{}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 200 {
+				d := backoffDelay(tt.config, tt.attempt)
+				if d > tt.max || (tt.max > 0 && d <= 0) {
+					t.Fatalf("backoffDelay = %s, want in (0, %s]", d, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"120", 2 * time.Minute},
+		{" 5 ", 5 * time.Second},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"0", 0},
+		{"-3", 0},
+		{"soon", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: 400}, true},
+		{&APIError{StatusCode: 422}, true},
+		{&APIError{StatusCode: 408}, false},
+		{&APIError{StatusCode: 429}, false},
+		{&APIError{StatusCode: 503}, false},
+		{errors.New("connection refused"), false},
+		{errors.Join(errors.New("wrapped"), &APIError{StatusCode: 404}), true},
+	}
+	for _, tt := range tests {
+		if got := IsPermanent(tt.err); got != tt.want {
+			t.Errorf("IsPermanent(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSendWithRetry(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond, Multiplier: 2}
+	transient := errors.New("connection reset")
+	tests := []struct {
+		name      string
+		results   []error // ответы send по порядку; после конца - успех
+		wantCalls int
+		wantErr   string
+	}{
+		{"first try", nil, 1, ""},
+		{"recovers after transient errors", []error{transient, &APIError{StatusCode: 503}}, 3, ""},
+		{"gives up", []error{transient, transient, transient}, 3, "giving up after 3 attempts"},
+		{"permanent error not retried", []error{&APIError{StatusCode: 400}}, 1, "status: 400"},
+		{"retry-after beyond max backoff", []error{&APIError{StatusCode: 429, RetryAfter: time.Minute}}, 1, "server asked to retry after 1m0s"},
+		{"short retry-after honoured", []error{&APIError{StatusCode: 503, RetryAfter: time.Millisecond}}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := sendWithRetry(context.Background(), config, &circuitBreaker{}, func() error {
+				calls++
+				if calls <= len(tt.results) {
+					return tt.results[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("send called %d times, want %d", calls, tt.wantCalls)
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendWithRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	config := RetryConfig{MaxAttempts: 5, InitialBackoff: time.Hour, Multiplier: 2}
+	calls := 0
+	err := sendWithRetry(ctx, config, &circuitBreaker{}, func() error {
+		calls++
+		cancel()
+		return errors.New("canceled mid-flight")
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("sendWithRetry = %v after %d calls, want an error after 1 call", err, calls)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	config := RetryConfig{BreakerThreshold: 2, BreakerCooldown: 20 * time.Millisecond}
+	b := &circuitBreaker{}
+
+	// Первая неудача не размыкает автомат
+	b.failure(config)
+	if err := b.allow(config); err != nil {
+		t.Fatalf("breaker opened after 1 failure: %v", err)
+	}
+	b.failure(config)
+	if err := b.allow(config); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow = %v after threshold, want ErrCircuitOpen", err)
+	}
+
+	// sendWithRetry не вызывает send, пока автомат разомкнут
+	calls := 0
+	err := sendWithRetry(context.Background(), config, b, func() error { calls++; return nil })
+	if !errors.Is(err, ErrCircuitOpen) || calls != 0 {
+		t.Fatalf("sendWithRetry = %v with %d calls, want ErrCircuitOpen without calls", err, calls)
+	}
+
+	// После паузы пропускается одна пробная попытка
+	time.Sleep(config.BreakerCooldown + 5*time.Millisecond)
+	if err := b.allow(config); err != nil {
+		t.Fatalf("probe not allowed after cooldown: %v", err)
+	}
+	if err := b.allow(config); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second concurrent probe allowed: %v", err)
+	}
+
+	// Неудачная проба сразу размыкает автомат снова
+	b.failure(config)
+	if err := b.allow(config); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow = %v after failed probe, want ErrCircuitOpen", err)
+	}
+
+	// Успешная проба замыкает автомат и сбрасывает счетчик
+	time.Sleep(config.BreakerCooldown + 5*time.Millisecond)
+	if err := sendWithRetry(context.Background(), config, b, func() error { return nil }); err != nil {
+		t.Fatalf("probe send: %v", err)
+	}
+	b.failure(config)
+	if err := b.allow(config); err != nil {
+		t.Fatalf("breaker opened after 1 failure following recovery: %v", err)
+	}
+
+	// Отключенный автомат не размыкается
+	disabled := &circuitBreaker{}
+	for range 10 {
+		disabled.failure(RetryConfig{})
+	}
+	if err := disabled.allow(RetryConfig{}); err != nil {
+		t.Errorf("disabled breaker: %v", err)
+	}
+}
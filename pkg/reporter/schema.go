@@ -0,0 +1,209 @@
+package reporter
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:generate go run ./internal/schemagen report.schema.json
+
+// SchemaID идентификатор схемы отчета версии APIVersion
+const SchemaID = "urn:rpc-report:schema:system-report:" + APIVersion
+
+// reportSchema JSON Schema отчета, сгенерированная GenerateSchema.
+// После изменения типов отчета схему нужно пересоздать: go generate ./pkg/reporter
+//
+//go:embed report.schema.json
+var reportSchema []byte
+
+// ReportSchema возвращает JSON Schema (draft 2020-12) формата SystemReport
+func ReportSchema() []byte {
+	return reportSchema
+}
+
+// builtinSectionTypes типы данных встроенных секций
+var builtinSectionTypes = map[string]reflect.Type{
+	SectionHost:      reflect.TypeOf(&HostInfo{}),
+	SectionCPU:       reflect.TypeOf(&CPUInfo{}),
+	SectionMemory:    reflect.TypeOf(&MemoryInfo{}),
+	SectionDisk:      reflect.TypeOf([]DiskInfo{}),
+	SectionNetwork:   reflect.TypeOf(&NetworkInfo{}),
+	SectionProcesses: reflect.TypeOf([]ProcessInfo{}),
+	SectionDocker:    reflect.TypeOf([]DockerContainer{}),
+	SectionSecurity:  reflect.TypeOf(&SecurityStatus{}),
+}
+
+// zeroTime значение time.Time, которое encoding/json пишет для пустого времени
+const zeroTime = "0001-01-01T00:00:00Z"
+
+// schemaConstraints ограничения, которые не выводятся из типов Go:
+// "Тип.поле" дополняет схему поля, "Тип" - схему объекта
+var schemaConstraints = map[string]map[string]interface{}{
+	"SystemReport.api_version": {"minLength": 1},
+	"SystemReport.generated":   {"not": map[string]interface{}{"const": zeroTime}},
+	"SystemReport.reports":     {"type": "array", "minItems": 1},
+	"Report.host_id":           {"minLength": 1},
+	"Report.timestamp":         {"not": map[string]interface{}{"const": zeroTime}},
+	"Report.sections": {
+		"type":          "object",
+		"minProperties": 1,
+		// Номера встроенных секций или имена сторонних сборщиков
+		"propertyNames": map[string]interface{}{"pattern": "^([1-9][0-9]*|[^0-9].*)$"},
+	},
+	"Section.title": {"minLength": 1},
+	"Section.status": {
+		"enum": []interface{}{SectionStatusError, SectionStatusTimeout, SectionStatusUnavailable},
+	},
+	"Section": {
+		"dependentRequired": map[string]interface{}{"status": []interface{}{"error"}},
+	},
+}
+
+// GenerateSchema строит JSON Schema отчета по типам Go: поля без
+// omitempty обязательны, лишние поля запрещены, nil-срезы и указатели
+// без omitempty допускают null. Данные встроенных секций проверяются по
+// имени секции; данные сторонних секций не ограничиваются.
+func GenerateSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]interface{})}
+	g.ref(reflect.TypeOf(SystemReport{}))
+
+	// Схема данных секции выбирается по полю name
+	var sectionData []interface{}
+	for _, name := range sortedKeys(builtinSectionTypes) {
+		sectionData = append(sectionData, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"name": map[string]interface{}{"const": name}},
+				"required":   []interface{}{"name"},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{"data": g.schemaFor(builtinSectionTypes[name], true)},
+			},
+		})
+	}
+	g.defs["Section"].(map[string]interface{})["allOf"] = sectionData
+
+	schema := map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         SchemaID,
+		"title":       "SystemReport",
+		"description": "System report produced by reporter, API version " + APIVersion + ".",
+		"$ref":        "#/$defs/SystemReport",
+		"$defs":       g.defs,
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %v", err)
+	}
+	return append(data, '\n'), nil
+}
+
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ref добавляет структуру в $defs и возвращает ссылку на нее
+func (g *schemaGenerator) ref(t reflect.Type) map[string]interface{} {
+	name := t.Name()
+	if _, ok := g.defs[name]; !ok {
+		object := map[string]interface{}{"type": "object", "additionalProperties": false}
+		g.defs[name] = object
+
+		properties := make(map[string]interface{})
+		var required []interface{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if !f.IsExported() || tag == "-" {
+				continue
+			}
+			field, opts, _ := strings.Cut(tag, ",")
+			if field == "" {
+				field = f.Name
+			}
+			omitempty := strings.Contains(opts, "omitempty")
+			if !omitempty {
+				required = append(required, field)
+			}
+			prop := g.schemaFor(f.Type, !omitempty)
+			for k, v := range schemaConstraints[name+"."+field] {
+				prop[k] = v
+			}
+			properties[field] = prop
+		}
+		object["properties"] = properties
+		if len(required) > 0 {
+			object["required"] = required
+		}
+		for k, v := range schemaConstraints[name] {
+			object[k] = v
+		}
+	}
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+// schemaFor возвращает схему значения типа t; nullable - значение может
+// быть null (nil-срез, map или указатель без omitempty)
+func (g *schemaGenerator) schemaFor(t reflect.Type, nullable bool) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schemaFor(t.Elem(), false)
+		if nullable {
+			return nullableSchema(schema)
+		}
+		return schema
+	case reflect.Slice:
+		schema := map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem(), false)}
+		if nullable {
+			return nullableSchema(schema)
+		}
+		return schema
+	case reflect.Map:
+		schema := map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem(), false)}
+		if nullable {
+			return nullableSchema(schema)
+		}
+		return schema
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		return g.ref(t)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	}
+	// interface{}: любое значение
+	return map[string]interface{}{}
+}
+
+// nullableSchema разрешает схеме значение null
+func nullableSchema(schema map[string]interface{}) map[string]interface{} {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []interface{}{typ, "null"}
+		return schema
+	}
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,428 @@
+package reporter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Значения полей Fail2ban и UfwStatus
+const (
+	SecurityActive       = "active"
+	SecurityInactive     = "inactive"
+	SecurityNotInstalled = "not installed"
+)
+
+// SecurityConfig пути к источникам данных секции SECURITY STATUS.
+// Пустые поля заменяются значениями из DefaultSecurityConfig.
+type SecurityConfig struct {
+	Fail2banSocket    string        `yaml:"fail2ban_socket" toml:"fail2ban_socket"`         // Сокет работающего fail2ban-server
+	Fail2banConfigDir string        `yaml:"fail2ban_config_dir" toml:"fail2ban_config_dir"` // Каталог с jail.conf, jail.local и jail.d
+	Fail2banLog       string        `yaml:"fail2ban_log" toml:"fail2ban_log"`               // Журнал fail2ban с событиями Ban/Unban
+	UfwConfig         string        `yaml:"ufw_config" toml:"ufw_config"`                   // ufw.conf с параметром ENABLED
+	UfwRulesDirs      []string      `yaml:"ufw_rules_dirs" toml:"ufw_rules_dirs"`           // Каталоги с user.rules и user6.rules, по приоритету
+	AuthLogs          []string      `yaml:"auth_logs" toml:"auth_logs"`                     // Журналы аутентификации с событиями sshd
+	SSHWindow         time.Duration `yaml:"ssh_window" toml:"ssh_window"`                   // Окно подсчета неудачных входов по SSH
+
+	// Журналы пакетных менеджеров; ротированные копии (.1, .2.gz) читаются тоже
+	AptHistoryLog string `yaml:"apt_history_log" toml:"apt_history_log"`
+	DpkgLog       string `yaml:"dpkg_log" toml:"dpkg_log"`
+	DnfLog        string `yaml:"dnf_log" toml:"dnf_log"`
+	YumLog        string `yaml:"yum_log" toml:"yum_log"`
+}
+
+// DefaultSecurityConfig возвращает стандартные пути Debian/Ubuntu и RHEL
+func DefaultSecurityConfig() SecurityConfig {
+	return SecurityConfig{
+		Fail2banSocket:    "/var/run/fail2ban/fail2ban.sock",
+		Fail2banConfigDir: "/etc/fail2ban",
+		Fail2banLog:       "/var/log/fail2ban.log",
+		UfwConfig:         "/etc/ufw/ufw.conf",
+		UfwRulesDirs:      []string{"/etc/ufw", "/lib/ufw"},
+		AuthLogs:          []string{"/var/log/auth.log.1", "/var/log/auth.log", "/var/log/secure"},
+		SSHWindow:         24 * time.Hour,
+		AptHistoryLog:     "/var/log/apt/history.log",
+		DpkgLog:           "/var/log/dpkg.log",
+		DnfLog:            "/var/log/dnf.rpm.log",
+		YumLog:            "/var/log/yum.log",
+	}
+}
+
+func (c SecurityConfig) withDefaults() SecurityConfig {
+	def := DefaultSecurityConfig()
+	if c.Fail2banSocket == "" {
+		c.Fail2banSocket = def.Fail2banSocket
+	}
+	if c.Fail2banConfigDir == "" {
+		c.Fail2banConfigDir = def.Fail2banConfigDir
+	}
+	if c.Fail2banLog == "" {
+		c.Fail2banLog = def.Fail2banLog
+	}
+	if c.UfwConfig == "" {
+		c.UfwConfig = def.UfwConfig
+	}
+	if len(c.UfwRulesDirs) == 0 {
+		c.UfwRulesDirs = def.UfwRulesDirs
+	}
+	if len(c.AuthLogs) == 0 {
+		c.AuthLogs = def.AuthLogs
+	}
+	if c.SSHWindow <= 0 {
+		c.SSHWindow = def.SSHWindow
+	}
+	if c.AptHistoryLog == "" {
+		c.AptHistoryLog = def.AptHistoryLog
+	}
+	if c.DpkgLog == "" {
+		c.DpkgLog = def.DpkgLog
+	}
+	if c.DnfLog == "" {
+		c.DnfLog = def.DnfLog
+	}
+	if c.YumLog == "" {
+		c.YumLog = def.YumLog
+	}
+	return c
+}
+
+// securityCollector собирает секцию SECURITY STATUS
+type securityCollector struct {
+	config SecurityConfig
+	now    func() time.Time
+}
+
+func newSecurityCollector(config SecurityConfig) *securityCollector {
+	return &securityCollector{config: config.withDefaults(), now: time.Now}
+}
+
+func (s *securityCollector) collect(ctx context.Context) (*SecurityStatus, error) {
+	status := &SecurityStatus{
+		LastUpdates:    "unknown",
+		SSHWindowHours: s.config.SSHWindow.Hours(),
+	}
+
+	run, err := s.lastUpgrade(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if run != nil {
+		days := int(s.now().Sub(run.Time).Hours() / 24)
+		status.LastUpdates = run.Time.Format("2006-01-02")
+		status.LastUpgrade = &run.Time
+		status.PackagesUpgraded = run.Packages
+		status.DaysSinceUpgrade = &days
+		status.UpdatesLog = run.Source
+	}
+
+	status.Fail2ban, status.Fail2banJails = s.fail2banStatus(ctx)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	status.UfwStatus, status.UfwRules = s.ufwStatus()
+
+	attempts, source, err := s.sshFailedAttempts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.SSHFailedAttempts = attempts
+	status.SSHLog = source
+
+	return status, nil
+}
+
+// fail2banStatus определяет состояние fail2ban и число заблокированных адресов по jail
+func (s *securityCollector) fail2banStatus(ctx context.Context) (string, []Fail2banJail) {
+	enabled, confErr := readFail2banJails(s.config.Fail2banConfigDir)
+	banned, logErr := readFail2banLog(s.config.Fail2banLog)
+	if errors.Is(confErr, fs.ErrNotExist) && errors.Is(logErr, fs.ErrNotExist) {
+		return SecurityNotInstalled, nil
+	}
+
+	state := SecurityInactive
+	if fail2banListening(ctx, s.config.Fail2banSocket) {
+		state = SecurityActive
+	}
+
+	names := make(map[string]bool)
+	for _, name := range enabled {
+		names[name] = true
+	}
+	for name := range banned {
+		names[name] = true
+	}
+
+	jails := make([]Fail2banJail, 0, len(names))
+	for name := range names {
+		jails = append(jails, Fail2banJail{Name: name, Banned: len(banned[name])})
+	}
+	sort.Slice(jails, func(i, j int) bool { return jails[i].Name < jails[j].Name })
+
+	return state, jails
+}
+
+// fail2banListening проверяет, что fail2ban-server принимает соединения на
+// сокете. Файл сокета остается после аварийной остановки сервера, поэтому
+// одного его наличия недостаточно.
+func fail2banListening(ctx context.Context, socket string) bool {
+	dialer := &net.Dialer{Timeout: time.Second}
+	conn, err := dialer.DialContext(ctx, "unix", socket)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// readFail2banJails возвращает включенные jail из конфигурации в порядке
+// чтения fail2ban: jail.conf, jail.d/*.conf, jail.local, jail.d/*.local
+func readFail2banJails(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	files := []string{filepath.Join(dir, "jail.conf")}
+	confs, _ := filepath.Glob(filepath.Join(dir, "jail.d", "*.conf"))
+	files = append(files, confs...)
+	files = append(files, filepath.Join(dir, "jail.local"))
+	locals, _ := filepath.Glob(filepath.Join(dir, "jail.d", "*.local"))
+	files = append(files, locals...)
+
+	defaultEnabled := false
+	jailEnabled := make(map[string]*bool)
+	var order []string
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		section := ""
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				section = strings.TrimSpace(line[1 : len(line)-1])
+				if section != "DEFAULT" && section != "INCLUDES" {
+					if _, ok := jailEnabled[section]; !ok {
+						jailEnabled[section] = nil
+						order = append(order, section)
+					}
+				}
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) != "enabled" {
+				continue
+			}
+			on := parseBool(strings.TrimSpace(value))
+			switch section {
+			case "DEFAULT":
+				defaultEnabled = on
+			case "", "INCLUDES":
+			default:
+				jailEnabled[section] = &on
+			}
+		}
+		f.Close()
+	}
+
+	var enabled []string
+	for _, name := range order {
+		on := defaultEnabled
+		if v := jailEnabled[name]; v != nil {
+			on = *v
+		}
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled, nil
+}
+
+var (
+	fail2banBanRe  = regexp.MustCompile(`\[([^\]]+)\]\s+(Restore Ban|Ban|Unban)\s+(\S+)`)
+	fail2banJailRe = regexp.MustCompile(`Jail '([^']+)' (started|stopped)`)
+)
+
+// readFail2banLog восстанавливает по журналу множества заблокированных адресов.
+// Запуск или остановка jail сбрасывает его множество: после перезапуска
+// fail2ban пишет "Restore Ban" для восстановленных блокировок.
+func readFail2banLog(path string) (map[string]map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	banned := make(map[string]map[string]bool)
+	err = readLogLines(f, maxLogLine, func(line string) {
+		if m := fail2banJailRe.FindStringSubmatch(line); m != nil {
+			if m[2] == "started" {
+				banned[m[1]] = make(map[string]bool)
+			} else {
+				delete(banned, m[1])
+			}
+			return
+		}
+		m := fail2banBanRe.FindStringSubmatch(line)
+		if m == nil {
+			return
+		}
+		jail, action, ip := m[1], m[2], m[3]
+		if banned[jail] == nil {
+			banned[jail] = make(map[string]bool)
+		}
+		if action == "Unban" {
+			delete(banned[jail], ip)
+		} else {
+			banned[jail][ip] = true
+		}
+	})
+	return banned, err
+}
+
+// ufwStatus читает состояние ufw из ufw.conf и считает пользовательские правила
+func (s *securityCollector) ufwStatus() (string, int) {
+	data, err := os.ReadFile(s.config.UfwConfig)
+	if err != nil {
+		return SecurityNotInstalled, 0
+	}
+
+	state := SecurityInactive
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && key == "ENABLED" && parseBool(strings.Trim(value, `"' `)) {
+			state = SecurityActive
+		}
+	}
+
+	// Правила берутся из первого каталога, где они есть
+	for _, dir := range s.config.UfwRulesDirs {
+		rules, found := 0, false
+		for _, name := range []string{"user.rules", "user6.rules"} {
+			n, err := countUfwRules(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			found = true
+			rules += n
+		}
+		if found {
+			return state, rules
+		}
+	}
+	return state, 0
+}
+
+// countUfwRules считает правила по маркерам "### tuple ###" в user.rules
+func countUfwRules(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "### tuple ###") {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+var sshFailedRe = regexp.MustCompile(`sshd(-session)?\[\d+\]: Failed \S+ for `)
+
+// sshFailedAttempts считает неудачные входы по SSH за окно SSHWindow.
+// Возвращает также список прочитанных журналов; пустой список означает,
+// что ни один журнал недоступен.
+func (s *securityCollector) sshFailedAttempts(ctx context.Context) (int, string, error) {
+	now := s.now()
+	since := now.Add(-s.config.SSHWindow)
+
+	count := 0
+	var sources []string
+	for _, path := range s.config.AuthLogs {
+		if err := ctx.Err(); err != nil {
+			return 0, "", err
+		}
+		n, err := countSSHFailures(path, since, now)
+		if err != nil {
+			continue
+		}
+		count += n
+		sources = append(sources, path)
+	}
+	return count, strings.Join(sources, ","), nil
+}
+
+func countSSHFailures(path string, since, now time.Time) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	err = readLogLines(f, maxLogLine, func(line string) {
+		if !sshFailedRe.MatchString(line) {
+			return
+		}
+		ts, ok := parseSyslogTime(line, now)
+		if !ok || ts.Before(since) || ts.After(now) {
+			return
+		}
+		count++
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return count, nil
+}
+
+// parseSyslogTime разбирает время в начале строки журнала: RFC3339
+// (rsyslog с высокой точностью) или классический "Jan _2 15:04:05" без года
+func parseSyslogTime(line string, now time.Time) (time.Time, bool) {
+	if field, _, ok := strings.Cut(line, " "); ok {
+		if ts, err := time.Parse(time.RFC3339Nano, field); err == nil {
+			return ts, true
+		}
+	}
+
+	if len(line) < len(time.Stamp) {
+		return time.Time{}, false
+	}
+	ts, err := time.ParseInLocation(time.Stamp, line[:len(time.Stamp)], now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	ts = ts.AddDate(now.Year(), 0, 0)
+	// Записи конца прошлого года, прочитанные в январе
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts, true
+}
+
+func parseBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package reporter
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureSecurityConfig указывает все источники на testdata; отсутствующие
+// файлы задаются явно, чтобы не читать журналы хоста
+func fixtureSecurityConfig() SecurityConfig {
+	dir := securityTestdata
+	return SecurityConfig{
+		Fail2banSocket:    filepath.Join(dir, "missing", "fail2ban.sock"),
+		Fail2banConfigDir: filepath.Join(dir, "fail2ban"),
+		Fail2banLog:       filepath.Join(dir, "log", "fail2ban.log"),
+		UfwConfig:         filepath.Join(dir, "ufw", "ufw.conf"),
+		UfwRulesDirs:      []string{filepath.Join(dir, "missing"), filepath.Join(dir, "ufw")},
+		AuthLogs: []string{
+			filepath.Join(dir, "log", "auth.log.1"),
+			filepath.Join(dir, "log", "auth.log"),
+			filepath.Join(dir, "log", "secure"),
+		},
+		SSHWindow:     24 * time.Hour,
+		AptHistoryLog: filepath.Join(dir, "log", "apt", "history.log"),
+		DpkgLog:       filepath.Join(dir, "log", "dpkg.log"),
+		DnfLog:        filepath.Join(dir, "log", "dnf.rpm.log"),
+		YumLog:        filepath.Join(dir, "log", "yum.log"),
+	}
+}
+
+func TestSecurityCollect(t *testing.T) {
+	status, err := newFixtureCollector(fixtureSecurityConfig()).collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	days := 2
+	upgrade := time.Date(2024, 3, 8, 6, 25, 40, 0, time.UTC)
+	logs := fixtureSecurityConfig().AuthLogs
+	want := &SecurityStatus{
+		Fail2ban:          SecurityInactive,
+		Fail2banJails:     []Fail2banJail{{Name: "nginx-http-auth", Banned: 1}, {Name: "sshd", Banned: 2}},
+		UfwStatus:         SecurityActive,
+		UfwRules:          3,
+		LastUpdates:       "2024-03-08",
+		LastUpgrade:       &upgrade,
+		PackagesUpgraded:  2,
+		DaysSinceUpgrade:  &days,
+		UpdatesLog:        fixtureSecurityConfig().AptHistoryLog,
+		SSHFailedAttempts: 6,
+		SSHWindowHours:    24,
+		SSHLog:            strings.Join(logs, ","),
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("collect =\n%+v\nwant\n%+v", status, want)
+	}
+}
+
+func TestSecurityNotInstalled(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	config := SecurityConfig{
+		Fail2banSocket:    missing,
+		Fail2banConfigDir: missing,
+		Fail2banLog:       missing,
+		UfwConfig:         missing,
+		UfwRulesDirs:      []string{missing},
+		AuthLogs:          []string{missing},
+		AptHistoryLog:     missing,
+		DpkgLog:           missing,
+		DnfLog:            missing,
+		YumLog:            missing,
+	}
+	status, err := newFixtureCollector(config).collect(context.Background())
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if status.Fail2ban != SecurityNotInstalled || status.UfwStatus != SecurityNotInstalled {
+		t.Errorf("fail2ban = %q, ufw = %q, want %q", status.Fail2ban, status.UfwStatus, SecurityNotInstalled)
+	}
+	if status.LastUpdates != "unknown" || status.LastUpgrade != nil || status.SSHLog != "" {
+		t.Errorf("unexpected data without logs: %+v", status)
+	}
+}
+
+func TestFail2banSocketState(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, socket string)
+		want  string
+	}{
+		{"missing socket", func(t *testing.T, socket string) {}, SecurityInactive},
+		{"stale socket file", func(t *testing.T, socket string) {
+			// Сервер упал и не удалил сокет: файл есть, соединений никто не принимает
+			ln, err := net.Listen("unix", socket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ln.(*net.UnixListener).SetUnlinkOnClose(false)
+			ln.Close()
+		}, SecurityInactive},
+		{"regular file", func(t *testing.T, socket string) {
+			if err := os.WriteFile(socket, nil, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}, SecurityInactive},
+		{"listening server", func(t *testing.T, socket string) {
+			ln, err := net.Listen("unix", socket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { ln.Close() })
+		}, SecurityActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := fixtureSecurityConfig()
+			config.Fail2banSocket = filepath.Join(t.TempDir(), "f2b.sock")
+			tt.setup(t, config.Fail2banSocket)
+
+			state, jails := newFixtureCollector(config).fail2banStatus(context.Background())
+			if state != tt.want {
+				t.Errorf("fail2ban = %q, want %q", state, tt.want)
+			}
+			if len(jails) != 2 {
+				t.Errorf("jails = %v, want 2 from fixture config", jails)
+			}
+		})
+	}
+}
+
+func TestReadFail2banJails(t *testing.T) {
+	jails, err := readFail2banJails(filepath.Join(securityTestdata, "fail2ban"))
+	if err != nil {
+		t.Fatalf("readFail2banJails: %v", err)
+	}
+	// postfix выключен в jail.local, recidive - в jail.d/override.local
+	want := []string{"sshd", "nginx-http-auth"}
+	if !reflect.DeepEqual(jails, want) {
+		t.Errorf("enabled jails = %v, want %v", jails, want)
+	}
+}
+
+func TestReadFail2banLog(t *testing.T) {
+	// Строка длиннее maxLogLine пропускается целиком, вместе с ее Ban
+	long := "[sshd] Ban 192.0.2.99 " + strings.Repeat("x", 2*maxLogLine) + "\n"
+	tests := []struct {
+		name string
+		log  string
+		want map[string]map[string]bool
+	}{
+		{
+			name: "ban and unban",
+			log: "[sshd] Ban 203.0.113.5\n" +
+				"[sshd] Ban 203.0.113.6\n" +
+				"[sshd] Unban 203.0.113.5\n",
+			want: map[string]map[string]bool{"sshd": {"203.0.113.6": true}},
+		},
+		{
+			name: "restart resets jail",
+			log: "[sshd] Ban 203.0.113.5\n" +
+				"Jail 'sshd' stopped\n" +
+				"Jail 'sshd' started\n" +
+				"[sshd] Restore Ban 203.0.113.6\n",
+			want: map[string]map[string]bool{"sshd": {"203.0.113.6": true}},
+		},
+		{
+			name: "stopped jail",
+			log:  "[sshd] Ban 203.0.113.5\nJail 'sshd' stopped\n",
+			want: map[string]map[string]bool{},
+		},
+		{
+			name: "long line skipped",
+			log:  "[sshd] Ban 203.0.113.5\n" + long + "[sshd] Ban 203.0.113.6",
+			want: map[string]map[string]bool{"sshd": {"203.0.113.5": true, "203.0.113.6": true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fail2ban.log")
+			if err := os.WriteFile(path, []byte(tt.log), 0644); err != nil {
+				t.Fatal(err)
+			}
+			banned, err := readFail2banLog(path)
+			if err != nil {
+				t.Fatalf("readFail2banLog: %v", err)
+			}
+			if !reflect.DeepEqual(banned, tt.want) {
+				t.Errorf("banned = %v, want %v", banned, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountSSHFailures(t *testing.T) {
+	since := fixtureNow.Add(-24 * time.Hour)
+	tests := []struct {
+		file string
+		want int
+	}{
+		// 13:00 позже момента сбора и не считается
+		{"auth.log", 3},
+		{"auth.log.1", 2},
+		{"secure", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := countSSHFailures(filepath.Join(securityTestdata, "log", tt.file), since, fixtureNow)
+			if err != nil {
+				t.Fatalf("countSSHFailures: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("failures = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSyslogTime(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		now  time.Time
+		want time.Time
+		ok   bool
+	}{
+		{"classic", "Mar 10 09:14:02 web1 sshd[1]: x", fixtureNow, time.Date(2024, 3, 10, 9, 14, 2, 0, time.UTC), true},
+		{"padded day", "Mar  8 10:00:00 web1 sshd[1]: x", fixtureNow, time.Date(2024, 3, 8, 10, 0, 0, 0, time.UTC), true},
+		{"rfc3339", "2024-03-10T08:00:00.5+02:00 db1 sshd[1]: x", fixtureNow, time.Date(2024, 3, 10, 6, 0, 0, 500000000, time.UTC), true},
+		{"previous year", "Dec 31 23:59:00 web1 sshd[1]: x", time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC), true},
+		{"garbage", "not a syslog line", fixtureNow, time.Time{}, false},
+		{"short", "Mar 10", fixtureNow, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseSyslogTime(tt.line, tt.now)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("parseSyslogTime = %v, %v; want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
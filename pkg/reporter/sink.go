@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Sink получатель собранного отчета: файл, API, экспорт в другой формат
+type Sink interface {
+	// Name возвращает имя приемника для сообщений об ошибках
+	Name() string
+	// Write передает отчет приемнику. Отчет общий для всех приемников
+	// и не должен изменяться.
+	Write(ctx context.Context, report *SystemReport) error
+}
+
+// NewSink создает приемник из функции
+func NewSink(name string, fn func(ctx context.Context, report *SystemReport) error) Sink {
+	return &funcSink{name: name, fn: fn}
+}
+
+type funcSink struct {
+	name string
+	fn   func(ctx context.Context, report *SystemReport) error
+}
+
+func (s *funcSink) Name() string { return s.name }
+
+func (s *funcSink) Write(ctx context.Context, report *SystemReport) error {
+	return s.fn(ctx, report)
+}
+
+// FileSink сохраняет отчет в JSON-файл (см. SaveReportToJSON)
+func FileSink(filename string) Sink {
+	return NewSink("file", func(ctx context.Context, report *SystemReport) error {
+		return SaveReportToJSON(report, filename)
+	})
+}
+
+// HashSink пишет в w хеш содержимого отчета без полей exclude (см. ContentHash)
+func HashSink(exclude []string, w io.Writer) Sink {
+	return NewSink("hash", func(ctx context.Context, report *SystemReport) error {
+		hash, err := ContentHash(report, exclude)
+		if err != nil {
+			return fmt.Errorf("failed to calculate report hash: %v", err)
+		}
+		_, err = fmt.Fprintf(w, "Report content hash: %s\n", hash)
+		return err
+	})
+}
+
+// APISink отправляет отчет на API через Reporter.Send
+func APISink(r *Reporter) Sink {
+	return NewSink("api", r.Send)
+}
+
+// Sinks возвращает приемники доставки по конфигурации: API и, если
+// включены, дополнительные цели (OTLP, InfluxDB, Graphite)
+func (r *Reporter) Sinks() []Sink {
+	config := r.GetConfig()
+	sinks := []Sink{APISink(r)}
+	if config.OTLP.Enabled {
+		sinks = append(sinks, OTLPSink(config))
+	}
+	if config.Influx.Enabled {
+		sinks = append(sinks, InfluxSink(config))
+	}
+	if config.Graphite.Enabled {
+		sinks = append(sinks, GraphiteSink(config))
+	}
+	return sinks
+}
+
+// Pipeline собирает отчет один раз и передает его всем приемникам по
+// порядку, поэтому сохраненный файл, напечатанный хеш и отправленный на
+// сервер отчет совпадают
+type Pipeline struct {
+	Reporter *Reporter
+	Sinks    []Sink
+}
+
+// NewPipeline создает конвейер из репортера и приемников
+func NewPipeline(r *Reporter, sinks ...Sink) *Pipeline {
+	return &Pipeline{Reporter: r, Sinks: sinks}
+}
+
+// Run собирает отчет и передает его приемникам. Ошибка одного приемника
+// не останавливает остальные: отчет сохраняется в файл, даже если API
+// недоступен. Возвращает отчет и объединенные ошибки приемников.
+func (p *Pipeline) Run(ctx context.Context) (*SystemReport, error) {
+	report, err := p.Reporter.GenerateReportContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error generating report: %v", err)
+	}
+	return report, p.Dispatch(ctx, report)
+}
+
+// Dispatch передает готовый отчет приемникам, как Run
+func (p *Pipeline) Dispatch(ctx context.Context, report *SystemReport) error {
+	var errs []error
+	for _, sink := range p.Sinks {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := sink.Write(ctx, report); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
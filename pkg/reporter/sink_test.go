@@ -0,0 +1,160 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingSink запоминает полученные отчеты
+type recordingSink struct {
+	name    string
+	err     error
+	reports []*SystemReport
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Write(ctx context.Context, report *SystemReport) error {
+	s.reports = append(s.reports, report)
+	return s.err
+}
+
+// apiRecorder принимает отчеты API и запоминает последний
+type apiRecorder struct {
+	mu      sync.Mutex
+	status  int
+	request *APIReportRequest
+}
+
+func (rcv *apiRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rcv.request = &APIReportRequest{}
+	if err := json.Unmarshal(body, rcv.request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if rcv.status != 0 {
+		w.WriteHeader(rcv.status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestPipelineRun(t *testing.T) {
+	discardLog(t)
+	api := &apiRecorder{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	r := agentReporter(t, server.URL, 0)
+	file := filepath.Join(t.TempDir(), "report.json")
+	var hashOut bytes.Buffer
+	first, last := &recordingSink{name: "first"}, &recordingSink{name: "last"}
+
+	report, err := NewPipeline(r, first, FileSink(file), HashSink(nil, &hashOut), APISink(r), last).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if r.LastReport() != report {
+		t.Error("Run returned a report other than the collected one")
+	}
+	for _, sink := range []*recordingSink{first, last} {
+		if len(sink.reports) != 1 || sink.reports[0] != report {
+			t.Errorf("%s sink got %d reports, want the collected report once", sink.name, len(sink.reports))
+		}
+	}
+
+	// Файл, хеш и API получили один и тот же отчет
+	saved, err := LoadReportFromJSON(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := ContentHash(report, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if savedHash, _ := ContentHash(saved, nil); savedHash != hash {
+		t.Errorf("saved file hash = %s, want %s", savedHash, hash)
+	}
+	if got := hashOut.String(); got != "Report content hash: "+hash+"\n" {
+		t.Errorf("hash sink wrote %q, want hash %s", got, hash)
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.request == nil {
+		t.Fatal("API received no report")
+	}
+	data, err := json.Marshal(api.request.Report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent SystemReport
+	if err := json.Unmarshal(data, &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sentHash, _ := ContentHash(&sent, nil); sentHash != hash {
+		t.Errorf("API report hash = %s, want %s", sentHash, hash)
+	}
+}
+
+func TestPipelineSinkErrors(t *testing.T) {
+	discardLog(t)
+	api := &apiRecorder{status: http.StatusInternalServerError}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	r := agentReporter(t, server.URL, 0)
+	boom := errors.New("disk full")
+	failing := &recordingSink{name: "failing", err: boom}
+	last := &recordingSink{name: "last"}
+	file := filepath.Join(t.TempDir(), "report.json")
+
+	report, err := NewPipeline(r, failing, APISink(r), FileSink(file), last).Run(context.Background())
+	if report == nil {
+		t.Fatal("Run returned no report")
+	}
+	if len(last.reports) != 1 {
+		t.Error("sinks after the failing ones were skipped")
+	}
+	if _, loadErr := LoadReportFromJSON(file); loadErr != nil {
+		t.Errorf("file sink after failed API: %v", loadErr)
+	}
+
+	// Ошибки приемников объединяются с их именами и не подменяются
+	if !errors.Is(err, boom) {
+		t.Errorf("error %v does not wrap the sink error", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("error %v does not wrap the API error", err)
+	}
+	for _, want := range []string{"failing: disk full", "api: "} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want containing %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "last:") || strings.Contains(err.Error(), "file:") {
+		t.Errorf("error = %v mentions sinks that succeeded", err)
+	}
+}
+
+func TestPipelineDispatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sink := &recordingSink{name: "sink"}
+	err := NewPipeline(nil, sink).Dispatch(ctx, sampleReport())
+	if !errors.Is(err, context.Canceled) || len(sink.reports) != 0 {
+		t.Errorf("Dispatch = %v with %d writes, want context.Canceled and none", err, len(sink.reports))
+	}
+}
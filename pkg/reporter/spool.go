@@ -0,0 +1,257 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Ограничения очереди неотправленных отчетов по умолчанию
+const (
+	DefaultSpoolMaxBytes = 50 * 1024 * 1024
+	DefaultSpoolMaxAge   = 7 * 24 * time.Hour
+)
+
+// spoolSeq различает файлы, созданные в одну наносекунду
+var spoolSeq atomic.Uint64
+
+// Spool хранит на диске отчеты, которые не удалось отправить, и
+// отдает их в порядке постановки. Каждый отчет - отдельный JSON-файл;
+// имя файла начинается со времени постановки, поэтому сортировка
+// по имени совпадает с порядком очереди. Отложенные файлы (.corrupt,
+// .rejected) не отправляются, но учитываются в ограничениях объема и
+// срока хранения и удаляются первыми.
+type Spool struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+}
+
+// NewSpool открывает (и при необходимости создает) каталог очереди.
+// Нулевые maxBytes и maxAge заменяются значениями по умолчанию.
+func NewSpool(dir string, maxBytes int64, maxAge time.Duration) (*Spool, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("spool directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %v", err)
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultSpoolMaxBytes
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultSpoolMaxAge
+	}
+	return &Spool{dir: dir, maxBytes: maxBytes, maxAge: maxAge}, nil
+}
+
+// DefaultSpoolDir возвращает каталог очереди в пользовательском кеше
+func DefaultSpoolDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "system-reporter", "spool")
+}
+
+// Dir возвращает каталог очереди
+func (s *Spool) Dir() string {
+	return s.dir
+}
+
+// Put ставит запрос в конец очереди. Запись атомарна: файл сначала
+// пишется во временный, затем переименовывается.
+func (s *Spool) Put(request *APIReportRequest) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spooled report: %v", err)
+	}
+	if int64(len(data)) > s.maxBytes {
+		return fmt.Errorf("report of %d bytes exceeds spool limit of %d bytes", len(data), s.maxBytes)
+	}
+
+	name := fmt.Sprintf("%020d-%06d.json", time.Now().UnixNano(), spoolSeq.Add(1)%1000000)
+	tmp := filepath.Join(s.dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write spool file: %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to commit spool file: %v", err)
+	}
+
+	// Отчет уже в очереди, ошибка очистки его не отменяет
+	s.pruneWithWarning()
+	return nil
+}
+
+// Len возвращает число отчетов в очереди
+func (s *Spool) Len() (int, error) {
+	entries, err := s.entries()
+	return len(entries), err
+}
+
+// Drain отправляет отчеты из очереди по порядку и удаляет отправленные.
+// На первой ошибке отправки останавливается, чтобы не нарушить порядок,
+// и возвращает число успешно отправленных отчетов вместе с ошибкой.
+// Отчеты, окончательно отвергнутые сервером (см. IsPermanent),
+// откладываются с расширением .rejected, чтобы не блокировать очередь.
+func (s *Spool) Drain(ctx context.Context, send func(ctx context.Context, request *APIReportRequest) error) (int, error) {
+	s.pruneWithWarning()
+	entries, err := s.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		path := filepath.Join(s.dir, e.name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return sent, fmt.Errorf("failed to read spool file: %v", err)
+		}
+		var request APIReportRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			// Поврежденный файл откладываем в сторону, чтобы не блокировать очередь
+			logf("Warning: moving corrupt spool file %s aside: %v\n", e.name, err)
+			if err := os.Rename(path, path+spoolCorruptSuffix); err != nil {
+				return sent, fmt.Errorf("failed to move corrupt spool file aside: %v", err)
+			}
+			continue
+		}
+
+		if err := send(ctx, &request); err != nil {
+			if IsPermanent(err) {
+				logf("Warning: server rejected spooled report %s, moving aside: %v\n", e.name, err)
+				if err := os.Rename(path, path+spoolRejectedSuffix); err != nil {
+					return sent, fmt.Errorf("failed to move rejected spool file aside: %v", err)
+				}
+				continue
+			}
+			return sent, err
+		}
+		if err := os.Remove(path); err != nil {
+			return sent, fmt.Errorf("failed to remove sent spool file: %v", err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Расширения отложенных файлов очереди
+const (
+	spoolCorruptSuffix  = ".corrupt"
+	spoolRejectedSuffix = ".rejected"
+)
+
+type spoolEntry struct {
+	name    string
+	size    int64
+	modTime time.Time
+	parked  bool // отложенный файл, который больше не отправляется
+}
+
+// entries возвращает отчеты, ожидающие отправки, от старых к новым
+func (s *Spool) entries() ([]spoolEntry, error) {
+	all, err := s.allEntries()
+	if err != nil {
+		return nil, err
+	}
+	var entries []spoolEntry
+	for _, e := range all {
+		if !e.parked {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// allEntries возвращает все файлы очереди, включая отложенные, от старых к новым
+func (s *Spool) allEntries() ([]spoolEntry, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %v", err)
+	}
+
+	var entries []spoolEntry
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		parked := strings.HasSuffix(name, ".json"+spoolCorruptSuffix) || strings.HasSuffix(name, ".json"+spoolRejectedSuffix)
+		if !parked && !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, spoolEntry{name: name, size: info.Size(), modTime: info.ModTime(), parked: parked})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries, nil
+}
+
+// pruneWithWarning очищает очередь, сообщая об ошибках очистки: файл,
+// который не удалось удалить, не должен останавливать отправку
+func (s *Spool) pruneWithWarning() {
+	if err := s.prune(); err != nil {
+		logf("Warning: %v\n", err)
+	}
+}
+
+// prune удаляет просроченные файлы и при превышении объема самые старые,
+// начиная с отложенных. Ошибки удаления не прерывают очистку и
+// возвращаются вместе.
+func (s *Spool) prune() error {
+	entries, err := s.allEntries()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	remove := func(e spoolEntry) {
+		if err := os.Remove(filepath.Join(s.dir, e.name)); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to remove spool file %s: %v", e.name, err))
+		}
+	}
+
+	cutoff := time.Now().Add(-s.maxAge)
+	var total int64
+	var parked, queued []spoolEntry
+	for _, e := range entries {
+		if e.modTime.Before(cutoff) {
+			logf("Warning: dropping spool file %s older than %s\n", e.name, s.maxAge)
+			remove(e)
+			continue
+		}
+		total += e.size
+		if e.parked {
+			parked = append(parked, e)
+		} else {
+			queued = append(queued, e)
+		}
+	}
+
+	for _, e := range append(parked, queued...) {
+		if total <= s.maxBytes {
+			break
+		}
+		logf("Warning: dropping spool file %s, spool exceeds %d bytes\n", e.name, s.maxBytes)
+		remove(e)
+		total -= e.size
+	}
+	return errors.Join(errs...)
+}
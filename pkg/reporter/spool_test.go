@@ -0,0 +1,176 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func spoolFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSpoolDrainOrder(t *testing.T) {
+	spool, err := NewSpool(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, agent := range []string{"a", "b", "c"} {
+		if err := spool.Put(&APIReportRequest{Agent: agent}); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	var got []string
+	failOn := "c"
+	sent, err := spool.Drain(context.Background(), func(ctx context.Context, r *APIReportRequest) error {
+		if r.Agent == failOn {
+			return errors.New("network down")
+		}
+		got = append(got, r.Agent)
+		return nil
+	})
+	if sent != 2 || err == nil {
+		t.Fatalf("Drain = %d, %v; want 2 and an error", sent, err)
+	}
+	if n, _ := spool.Len(); n != 1 {
+		t.Fatalf("Len = %d after failed drain, want 1", n)
+	}
+
+	failOn = ""
+	if sent, err := spool.Drain(context.Background(), func(ctx context.Context, r *APIReportRequest) error {
+		got = append(got, r.Agent)
+		return nil
+	}); sent != 1 || err != nil {
+		t.Fatalf("Drain = %d, %v; want 1, nil", sent, err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sent order = %v, want %v", got, want)
+	}
+}
+
+func TestSpoolParksBadEntries(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := NewSpool(dir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000001-000001.json"), []byte("{broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.Put(&APIReportRequest{Agent: "rejected"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.Put(&APIReportRequest{Agent: "ok"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := spool.Drain(context.Background(), func(ctx context.Context, r *APIReportRequest) error {
+		if r.Agent == "rejected" {
+			return &APIError{StatusCode: 400}
+		}
+		return nil
+	})
+	if sent != 1 || err != nil {
+		t.Fatalf("Drain = %d, %v; want 1, nil", sent, err)
+	}
+	if n, _ := spool.Len(); n != 0 {
+		t.Errorf("Len = %d, parked files must not be queued", n)
+	}
+
+	files := spoolFiles(t, dir)
+	if len(files) != 2 || filepath.Ext(files[0]) != spoolCorruptSuffix || filepath.Ext(files[1]) != spoolRejectedSuffix {
+		t.Errorf("spool files = %v, want one .corrupt and one .rejected", files)
+	}
+}
+
+func TestSpoolPrune(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour)
+	tests := []struct {
+		name     string
+		maxBytes int64
+		files    map[string]int // имя -> размер
+		aged     []string       // файлы старше maxAge
+		want     []string       // оставшиеся файлы
+	}{
+		{
+			name:     "parked files count toward size and go first",
+			maxBytes: 250,
+			files: map[string]int{
+				"01-000001.json.rejected": 100,
+				"02-000002.json":          100,
+				"03-000003.json.corrupt":  100,
+			},
+			want: []string{"02-000002.json", "03-000003.json.corrupt"},
+		},
+		{
+			name:     "oldest queued dropped after parked",
+			maxBytes: 150,
+			files: map[string]int{
+				"01-000001.json":         100,
+				"02-000002.json":         100,
+				"03-000003.json.corrupt": 100,
+			},
+			want: []string{"02-000002.json"},
+		},
+		{
+			name:     "expired parked files removed",
+			maxBytes: 1000,
+			files: map[string]int{
+				"01-000001.json.rejected": 10,
+				"02-000002.json.corrupt":  10,
+				"03-000003.json":          10,
+			},
+			aged: []string{"01-000001.json.rejected", "02-000002.json.corrupt"},
+			want: []string{"03-000003.json"},
+		},
+		{
+			name:     "unrelated files ignored",
+			maxBytes: 10,
+			files: map[string]int{
+				"notes.txt": 100,
+			},
+			want: []string{"notes.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, size := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, name := range tt.aged {
+				if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+					t.Fatal(err)
+				}
+			}
+			spool, err := NewSpool(dir, tt.maxBytes, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := spool.prune(); err != nil {
+				t.Fatalf("prune: %v", err)
+			}
+			if got := spoolFiles(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package reporter
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"sort"
@@ -26,19 +27,34 @@ func bytesToMB(bytes uint64) float64 {
 
 // GetHostID возвращает host_id (hostname) системы
 func GetHostID() string {
-	hostInfo, err := host.Info()
+	return getHostID(context.Background())
+}
+
+func getHostID(ctx context.Context) string {
+	hostInfo, err := host.InfoWithContext(ctx)
 	if err != nil {
 		return "unknown-host"
 	}
 	return hostInfo.Hostname
 }
 
-// GenerateSystemReport генерирует полный системный отчет
+// GenerateSystemReport генерирует полный системный отчет встроенными секциями
 func GenerateSystemReport() (*SystemReport, error) {
-	hostID := GetHostID()
+	return GenerateSystemReportContext(context.Background())
+}
+
+// GenerateSystemReportContext генерирует системный отчет с учетом отмены ctx
+func GenerateSystemReportContext(ctx context.Context) (*SystemReport, error) {
+	return New(nil).GenerateReportContext(ctx)
+}
+
+// generateReport собирает отчет с помощью переданных сборщиков.
+// Ключи секций постоянны для каждого сборщика (см. sectionKey).
+func generateReport(ctx context.Context, collectors []Collector, opts collectOptions) (*SystemReport, error) {
+	hostID := getHostID(ctx)
 
 	report := &SystemReport{
-		APIVersion: "1.0",
+		APIVersion: APIVersion,
 		Generated:  time.Now(),
 		TotalHosts: 1,
 		Reports: []Report{
@@ -46,51 +62,22 @@ func GenerateSystemReport() (*SystemReport, error) {
 				HostID:       hostID,
 				ReportNumber: 1,
 				Timestamp:    time.Now(),
-				Sections:     make(map[string]Section),
 			},
 		},
 	}
 
-	// Собираем данные по секциям
-	sections := map[string]func() (interface{}, error){
-		"1": getHostInformation,
-		"2": getCPUInformation,
-		"3": getMemoryInformation,
-		"4": getDiskInformation,
-		"5": getNetworkInformation,
-		"6": getTopProcessesByMemory,
-		"7": getDockerContainers,
-		"8": getSecurityStatus,
-	}
-
-	titles := map[string]string{
-		"1": "HOST INFORMATION",
-		"2": "CPU INFORMATION", 
-		"3": "MEMORY INFORMATION",
-		"4": "DISK INFORMATION",
-		"5": "NETWORK INFORMATION",
-		"6": "TOP PROCESSES BY MEMORY",
-		"7": "DOCKER CONTAINERS",
-		"8": "SECURITY STATUS",
-	}
-
-	for key, fn := range sections {
-		data, err := fn()
-		if err != nil {
-			fmt.Printf("Warning: failed to get %s: %v\n", titles[key], err)
-			continue
-		}
-		report.Reports[0].Sections[key] = Section{
-			Title: titles[key],
-			Data:  data,
-		}
+	// Собираем данные по секциям параллельно
+	sections, err := collectSections(ctx, collectors, opts)
+	if err != nil {
+		return nil, err
 	}
+	report.Reports[0].Sections = sections
 
 	return report, nil
 }
 
-func getHostInformation() (*HostInfo, error) {
-	hostInfo, err := host.Info()
+func getHostInformation(ctx context.Context) (*HostInfo, error) {
+	hostInfo, err := host.InfoWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -106,18 +93,18 @@ func getHostInformation() (*HostInfo, error) {
 	}, nil
 }
 
-func getCPUInformation() (*CPUInfo, error) {
-	cpuInfo, err := cpu.Info()
+func getCPUInformation(ctx context.Context) (*CPUInfo, error) {
+	cpuInfo, err := cpu.InfoWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	percent, err := cpu.Percent(time.Second, false)
+	percent, err := cpu.PercentWithContext(ctx, time.Second, false)
 	if err != nil {
 		return nil, err
 	}
 
-	loadAvg, err := load.Avg()
+	loadAvg, err := load.AvgWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -142,26 +129,26 @@ func getCPUInformation() (*CPUInfo, error) {
 	}, nil
 }
 
-func getMemoryInformation() (*MemoryInfo, error) {
-	vmem, err := mem.VirtualMemory()
+func getMemoryInformation(ctx context.Context) (*MemoryInfo, error) {
+	vmem, err := mem.VirtualMemoryWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	swap, err := mem.SwapMemory()
+	swap, err := mem.SwapMemoryWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	return &MemoryInfo{
 		RAM: RAMInfo{
-			TotalGB:      bytesToGB(vmem.Total),
-			AvailableGB:  bytesToGB(vmem.Available),
-			UsedGB:       bytesToGB(vmem.Used),
-			UsedPercent:  vmem.UsedPercent,
-			FreeGB:       bytesToGB(vmem.Free),
-			CachedGB:     bytesToGB(vmem.Cached),
-			BuffersMB:    bytesToMB(vmem.Buffers),
+			TotalGB:     bytesToGB(vmem.Total),
+			AvailableGB: bytesToGB(vmem.Available),
+			UsedGB:      bytesToGB(vmem.Used),
+			UsedPercent: vmem.UsedPercent,
+			FreeGB:      bytesToGB(vmem.Free),
+			CachedGB:    bytesToGB(vmem.Cached),
+			BuffersMB:   bytesToMB(vmem.Buffers),
 		},
 		Swap: SwapInfo{
 			TotalGB:     bytesToGB(swap.Total),
@@ -171,15 +158,15 @@ func getMemoryInformation() (*MemoryInfo, error) {
 	}, nil
 }
 
-func getDiskInformation() ([]DiskInfo, error) {
-	partitions, err := disk.Partitions(false)
+func getDiskInformation(ctx context.Context) ([]DiskInfo, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, false)
 	if err != nil {
 		return nil, err
 	}
 
 	var disks []DiskInfo
 	for _, partition := range partitions {
-		usage, err := disk.Usage(partition.Mountpoint)
+		usage, err := disk.UsageWithContext(ctx, partition.Mountpoint)
 		if err != nil {
 			continue
 		}
@@ -198,13 +185,13 @@ func getDiskInformation() ([]DiskInfo, error) {
 	return disks, nil
 }
 
-func getNetworkInformation() (*NetworkInfo, error) {
-	interfaces, err := net.Interfaces()
+func getNetworkInformation(ctx context.Context) (*NetworkInfo, error) {
+	interfaces, err := net.InterfacesWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ioCounters, err := net.IOCounters(true)
+	ioCounters, err := net.IOCountersWithContext(ctx, true)
 	if err != nil {
 		return nil, err
 	}
@@ -242,8 +229,8 @@ func getNetworkInformation() (*NetworkInfo, error) {
 	return &NetworkInfo{Interfaces: ifaceList}, nil
 }
 
-func getTopProcessesByMemory() ([]ProcessInfo, error) {
-	processes, err := process.Processes()
+func getTopProcessesByMemory(ctx context.Context) ([]ProcessInfo, error) {
+	processes, err := process.ProcessesWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -253,18 +240,21 @@ func getTopProcessesByMemory() ([]ProcessInfo, error) {
 		if len(procList) >= 20 {
 			break
 		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-		name, err := p.Name()
+		name, err := p.NameWithContext(ctx)
 		if err != nil {
 			continue
 		}
 
-		memInfo, err := p.MemoryInfo()
+		memInfo, err := p.MemoryInfoWithContext(ctx)
 		if err != nil || memInfo == nil {
 			continue
 		}
 
-		cpuPercent, err := p.CPUPercent()
+		cpuPercent, err := p.CPUPercentWithContext(ctx)
 		if err != nil {
 			cpuPercent = 0
 		}
@@ -287,16 +277,3 @@ func getTopProcessesByMemory() ([]ProcessInfo, error) {
 
 	return procList, nil
 }
-
-func getDockerContainers() ([]DockerContainer, error) {
-	return []DockerContainer{}, nil
-}
-
-func getSecurityStatus() (*SecurityStatus, error) {
-	return &SecurityStatus{
-		Fail2ban:          "unknown",
-		UfwStatus:         "unknown", 
-		LastUpdates:       time.Now().Format("2006-01-02"),
-		SSHFailedAttempts: 0,
-	}, nil
-}
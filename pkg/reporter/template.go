@@ -0,0 +1,258 @@
+package reporter
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var bundledTemplates embed.FS
+
+// BundledTemplates возвращает имена встроенных шаблонов
+func BundledTemplates() []string {
+	entries, _ := bundledTemplates.ReadDir("templates")
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".tmpl"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// LoadTemplate загружает шаблон отчета. Имя без "/" и расширения, совпадающее
+// со встроенным шаблоном (см. BundledTemplates), выбирает встроенный
+// шаблон; иначе name - путь к файлу.
+func LoadTemplate(name string) (*template.Template, error) {
+	var text []byte
+	var err error
+	if isBundledTemplate(name) {
+		text, err = bundledTemplates.ReadFile("templates/" + name + ".tmpl")
+	} else {
+		text, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %v", err)
+	}
+	return ParseTemplate(path.Base(name), string(text))
+}
+
+func isBundledTemplate(name string) bool {
+	if strings.ContainsAny(name, `/\.`) {
+		return false
+	}
+	for _, bundled := range BundledTemplates() {
+		if name == bundled {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTemplate разбирает текст шаблона с функциями TemplateFuncs
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate выполняет шаблон над отчетом: точка шаблона - *SystemReport
+func RenderTemplate(report *SystemReport, w io.Writer, tmpl *template.Template) error {
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render template: %v", err)
+	}
+	return nil
+}
+
+// TemplateExporter сохраняет отчет, выполненный через шаблон
+func TemplateExporter(tmpl *template.Template) Exporter {
+	return &streamExporter{format: "template", render: func(report *SystemReport, w io.Writer) error {
+		return RenderTemplate(report, w, tmpl)
+	}}
+}
+
+// TemplateFuncs возвращает функции, доступные шаблонам отчета:
+//
+//	section REPORT NAME  данные секции по имени сборщика (host, cpu, disk, ...):
+//	                     *HostInfo, *CPUInfo, []DiskInfo и т.д.; nil, если
+//	                     секции нет или ее не удалось собрать
+//	sectionStatus REPORT NAME  статус секции: "" - успех, "missing" - нет секции
+//	gb, mb, bytes VALUE  объем в гигабайтах, мегабайтах или байтах: "1.5 GiB"
+//	percent VALUE        "12.3%"
+//	bar VALUE [WIDTH]    шкала процента: "[####------]", по умолчанию 10 символов
+//	uptime HOURS         "3d 4h"
+//	sortBy FIELD LIST    копия среза структур, отсортированная по полю (имя
+//	                     Go или JSON) по возрастанию
+//	reverse LIST, first N LIST  обратный порядок, первые N элементов
+//	json VALUE, join LIST SEP, upper, lower, pad WIDTH S
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"section":       templateSection,
+		"sectionStatus": templateSectionStatus,
+		"gb":            formatGB,
+		"mb":            func(mb float64) string { return formatBytes(mb * 1024 * 1024) },
+		"bytes":         func(b float64) string { return formatBytes(b) },
+		"percent":       formatPercent,
+		"bar":           templateBar,
+		"uptime":        formatUptime,
+		"sortBy":        templateSortBy,
+		"reverse":       templateReverse,
+		"first":         templateFirst,
+		"join":          strings.Join,
+		"upper":         strings.ToUpper,
+		"lower":         strings.ToLower,
+		"pad":           func(width int, s string) string { return pad(s, width, false) },
+		"json": func(v interface{}) (string, error) {
+			raw, err := json.Marshal(v)
+			return string(raw), err
+		},
+	}
+}
+
+// templateSection возвращает типизированные данные секции по имени
+func templateSection(report Report, name string) interface{} {
+	for _, key := range sortedSectionKeys(report.Sections) {
+		section := report.Sections[key]
+		if sectionName(section) != name {
+			continue
+		}
+		if section.Status != "" || section.Data == nil {
+			return nil
+		}
+		switch name {
+		case SectionHost:
+			return decodeSectionData[*HostInfo](section.Data)
+		case SectionCPU:
+			return decodeSectionData[*CPUInfo](section.Data)
+		case SectionMemory:
+			return decodeSectionData[*MemoryInfo](section.Data)
+		case SectionDisk:
+			return decodeSectionData[[]DiskInfo](section.Data)
+		case SectionNetwork:
+			return decodeSectionData[*NetworkInfo](section.Data)
+		case SectionProcesses:
+			return decodeSectionData[[]ProcessInfo](section.Data)
+		case SectionDocker:
+			return decodeSectionData[[]DockerContainer](section.Data)
+		case SectionSecurity:
+			return decodeSectionData[*SecurityStatus](section.Data)
+		}
+		return section.Data
+	}
+	return nil
+}
+
+func templateSectionStatus(report Report, name string) string {
+	for _, section := range report.Sections {
+		if sectionName(section) == name {
+			return section.Status
+		}
+	}
+	return "missing"
+}
+
+// templateBar рисует шкалу процента из width символов
+func templateBar(percent float64, width ...int) string {
+	n := 10
+	if len(width) > 0 && width[0] > 0 {
+		n = width[0]
+	}
+	filled := int(min(max(percent, 0), 100)/100*float64(n) + 0.5)
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", n-filled) + "]"
+}
+
+// templateSortBy сортирует копию среза структур (или указателей на
+// структуры) по полю field
+func templateSortBy(field string, list interface{}) (interface{}, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("sortBy: expected a slice, got %T", list)
+	}
+	keys := make([]reflect.Value, v.Len())
+	order := make([]int, v.Len())
+	for i := range keys {
+		key, err := structField(v.Index(i), field)
+		if err != nil {
+			return nil, err
+		}
+		keys[i], order[i] = key, i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return lessValue(keys[order[i]], keys[order[j]]) })
+
+	sorted := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	for i, from := range order {
+		sorted.Index(i).Set(v.Index(from))
+	}
+	return sorted.Interface(), nil
+}
+
+// structField возвращает поле структуры по имени Go или тегу json;
+// для nil-указателя - пустое значение
+func structField(v reflect.Value, name string) (reflect.Value, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("sortBy: %s is not a struct", v.Type())
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.IsExported() && (f.Name == name || tag == name) {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("sortBy: %s has no field %q", t, name)
+}
+
+// lessValue сравнивает значения полей; пустые значения идут первыми
+func lessValue(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return !a.IsValid() && b.IsValid()
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
+
+func templateReverse(list interface{}) (interface{}, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("reverse: expected a slice, got %T", list)
+	}
+	reversed := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		reversed.Index(v.Len() - 1 - i).Set(v.Index(i))
+	}
+	return reversed.Interface(), nil
+}
+
+func templateFirst(n int, list interface{}) (interface{}, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("first: expected a slice, got %T", list)
+	}
+	return v.Slice(0, min(max(n, 0), v.Len())).Interface(), nil
+}
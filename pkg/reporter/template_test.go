@@ -0,0 +1,112 @@
+package reporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBundledTemplates(t *testing.T) {
+	motd := "\n" +
+		" Welcome to web1\n" +
+		" debian 12.5, kernel 6.1.0-18-amd64, up 10d 0h\n" +
+		" Load:   0.50 0.40 0.30 (8 CPUs)\n"
+	disks := " Disks:\n" +
+		"   /var/lib/data store  [##################--] 90.0% of 500.0 GiB\n" +
+		"   /                    [########------------] 40.0% of 100.0 GiB\n" +
+		"\n"
+
+	tests := []struct {
+		name     string
+		template string
+		report   *SystemReport
+		want     string
+	}{
+		{"motd", "motd", sampleReport(), motd +
+			" Memory: [########------------] 37.5% of 16.0 GiB\n" +
+			" Swap:   [#####---------------] 25.0% of 2.0 GiB\n" + disks},
+		{"motd failed sections", "motd", failedSectionsReport(), motd + disks},
+		{"status", "status", sampleReport(),
+			"web1 cpu=12.5% load=0.50 mem=37.5% disk=90.0%(/var/lib/data store)\n"},
+		{"status failed sections", "status", failedSectionsReport(),
+			"web1 cpu=12.5% load=0.50 disk=90.0%(/var/lib/data store) memory=timeout security=error\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := LoadTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := RenderTemplate(tt.report, &buf, tmpl); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output =\n%q\nwant\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	if got := BundledTemplates(); !reflect.DeepEqual(got, []string{"motd", "status"}) {
+		t.Errorf("BundledTemplates() = %v", got)
+	}
+}
+
+func TestLoadTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	file := func(name, text string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	custom := file("custom.tmpl", `{{range .Reports}}{{.HostID}}:{{range first 1 (reverse (sortBy "cpu_percent" (section . "processes")))}} {{.Name}}{{end}}{{end}}`)
+
+	tmpl, err := LoadTemplate(custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "report.txt")
+	discardLog(t)
+	if err := TemplateExporter(tmpl).Export(sampleReport(), out); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "web1: nginx: worker" {
+		t.Errorf("exported %q", data)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		err  string
+	}{
+		{"missing file", filepath.Join(dir, "absent.tmpl"), "failed to read template"},
+		// Имя с расширением - путь к файлу, а не встроенный шаблон
+		{"bundled name with extension", "motd.tmpl", "failed to read template"},
+		{"parse error", file("broken.tmpl", "{{range .Reports}"), "failed to parse template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadTemplate(tt.path); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("LoadTemplate(%q) = %v, want error containing %q", tt.path, err, tt.err)
+			}
+		})
+	}
+
+	// Ошибка выполнения не оставляет частично записанный файл
+	failing, err := ParseTemplate("failing", `{{range .Reports}}{{sortBy "pid" .HostID}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := filepath.Join(dir, "partial.txt")
+	if err := TemplateExporter(failing).Export(sampleReport(), partial); err == nil || !strings.Contains(err.Error(), "failed to render template") {
+		t.Errorf("Export = %v, want render error", err)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("file written after render error: %v", err)
+	}
+}
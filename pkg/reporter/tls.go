@@ -0,0 +1,93 @@
+package reporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TLSConfig настройки TLS клиента API
+type TLSConfig struct {
+	CAFile     string `yaml:"ca_file" toml:"ca_file"`         // PEM-бандл доверенных CA; заменяет системные корневые сертификаты
+	CertFile   string `yaml:"cert_file" toml:"cert_file"`     // Клиентский сертификат для mTLS
+	KeyFile    string `yaml:"key_file" toml:"key_file"`       // Ключ клиентского сертификата
+	MinVersion string `yaml:"min_version" toml:"min_version"` // Минимальная версия TLS: "1.2" или "1.3"
+	ServerName string `yaml:"server_name" toml:"server_name"` // Имя, которому должен соответствовать сертификат сервера
+	Required   bool   `yaml:"required" toml:"required"`       // Запретить отправку по http://
+
+	// Не проверять сертификат сервера; только для тестовых стендов
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify" toml:"insecure_skip_verify"`
+}
+
+// tlsVersions допустимые значения min_version; TLS 1.0 и 1.1 устарели
+// (RFC 8996) и не поддерживаются
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig строит *tls.Config по настройкам
+func newTLSConfig(config TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.MinVersion != "" {
+		version, ok := tlsVersions[config.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS version %q, use 1.2 or 1.3", config.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		if config.CertFile == "" || config.KeyFile == "" {
+			return nil, fmt.Errorf("both client certificate and key are required for mTLS")
+		}
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// newHTTPClient создает HTTP-клиент с таймаутом и настройками TLS
+func newHTTPClient(config TLSConfig, timeout time.Duration, targetURL string) (*http.Client, error) {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	if config.Required && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("TLS is required but URL %s is not https", targetURL)
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
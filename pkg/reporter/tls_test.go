@@ -0,0 +1,218 @@
+package reporter
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPKI тестовый CA с сертификатами сервера и клиента, записанными в PEM
+type testPKI struct {
+	caFile   string
+	certFile string // клиентский сертификат
+	keyFile  string
+	pool     *x509.CertPool
+	server   tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey := newTestKey(t)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "reporter test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key := newTestKey(t)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		if usage == x509.ExtKeyUsageServerAuth {
+			template.DNSNames = []string{"reports.test"}
+			template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der, key
+	}
+
+	pki := &testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client-key.pem"),
+		pool:     x509.NewCertPool(),
+	}
+	pki.pool.AddCert(ca)
+	writePEM(t, pki.caFile, "CERTIFICATE", caDER)
+
+	clientDER, clientKey := issue(2, "reporter-agent", x509.ExtKeyUsageClientAuth)
+	writePEM(t, pki.certFile, "CERTIFICATE", clientDER)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, pki.keyFile, "EC PRIVATE KEY", keyDER)
+
+	serverDER, serverKey := issue(3, "reports.test", x509.ExtKeyUsageServerAuth)
+	pki.server = tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+	return pki
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startTLSServer запускает HTTPS-сервер с сертификатом из pki;
+// configure дополняет его настройки TLS
+func startTLSServer(t *testing.T, pki *testPKI, configure func(*tls.Config)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{pki.server}}
+	// Ошибки рукопожатия ожидаемы в отрицательных случаях
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	if configure != nil {
+		configure(server.TLS)
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPClientTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	requireClientCert := func(c *tls.Config) {
+		c.ClientAuth = tls.RequireAndVerifyClientCert
+		c.ClientCAs = pki.pool
+	}
+	maxTLS12 := func(c *tls.Config) { c.MaxVersion = tls.VersionTLS12 }
+
+	tests := []struct {
+		name    string
+		server  func(*tls.Config)
+		config  TLSConfig
+		wantErr string
+	}{
+		{"custom CA", nil, TLSConfig{CAFile: pki.caFile}, ""},
+		{"system roots reject test CA", nil, TLSConfig{}, "certificate"},
+		{"insecure skip verify", nil, TLSConfig{InsecureSkipVerify: true}, ""},
+		{"server name pinned", nil, TLSConfig{CAFile: pki.caFile, ServerName: "reports.test"}, ""},
+		{"server name mismatch", nil, TLSConfig{CAFile: pki.caFile, ServerName: "other.test"}, "other.test"},
+		{"mTLS with client cert", requireClientCert, TLSConfig{CAFile: pki.caFile, CertFile: pki.certFile, KeyFile: pki.keyFile}, ""},
+		{"mTLS without client cert", requireClientCert, TLSConfig{CAFile: pki.caFile}, "certificate"},
+		{"min version met", maxTLS12, TLSConfig{CAFile: pki.caFile, MinVersion: "1.2"}, ""},
+		{"min version above server", maxTLS12, TLSConfig{CAFile: pki.caFile, MinVersion: "1.3"}, "version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startTLSServer(t, pki, tt.server)
+			client, err := newHTTPClient(tt.config, 5*time.Second, server.URL)
+			if err != nil {
+				t.Fatalf("newHTTPClient: %v", err)
+			}
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("request failed: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatal("request succeeded, want TLS error")
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	pki := newTestPKI(t)
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("no certificates here\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  TLSConfig
+		wantErr string
+	}{
+		{"TLS 1.0 rejected", TLSConfig{MinVersion: "1.0"}, "unsupported TLS version"},
+		{"TLS 1.1 rejected", TLSConfig{MinVersion: "1.1"}, "unsupported TLS version"},
+		{"missing CA file", TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, "failed to read CA bundle"},
+		{"CA file without certificates", TLSConfig{CAFile: empty}, "no certificates found"},
+		{"cert without key", TLSConfig{CertFile: pki.certFile}, "both client certificate and key"},
+		{"key does not match", TLSConfig{CertFile: pki.certFile, KeyFile: pki.caFile}, "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTLSConfig(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("newTLSConfig error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := newHTTPClient(TLSConfig{Required: true}, time.Second, "http://reports.test/api"); err == nil {
+		t.Error("plain http accepted with tls.required")
+	}
+	config, err := newTLSConfig(TLSConfig{})
+	if err != nil {
+		t.Fatalf("newTLSConfig: %v", err)
+	}
+	if config.MinVersion != tls.VersionTLS12 {
+		t.Errorf("default MinVersion = %x, want TLS 1.2", config.MinVersion)
+	}
+}
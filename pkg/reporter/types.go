@@ -6,12 +6,80 @@ import (
 
 // Конфигурация репортера
 type Config struct {
-	APIBaseURL     string
-	ReportEndpoint string
-	Timeout        time.Duration
-	AgentName      string
+	APIBaseURL     string        `yaml:"api_base_url" toml:"api_base_url"`
+	ReportEndpoint string        `yaml:"report_endpoint" toml:"report_endpoint"`
+	Timeout        time.Duration `yaml:"timeout" toml:"timeout"`
+	AgentName      string        `yaml:"agent_name" toml:"agent_name"`
+
+	// Аутентификация на API. Пустые значения берутся из файлов *File,
+	// затем из переменных окружения REPORTER_AUTH_TOKEN и REPORTER_HMAC_SECRET.
+	AuthToken      string `yaml:"auth_token" toml:"auth_token"` // Bearer-токен
+	AuthTokenFile  string `yaml:"auth_token_file" toml:"auth_token_file"`
+	HMACSecret     string `yaml:"hmac_secret" toml:"hmac_secret"` // Ключ подписи тела запроса HMAC-SHA256
+	HMACSecretFile string `yaml:"hmac_secret_file" toml:"hmac_secret_file"`
+
+	// TLS и mTLS для соединения с API
+	TLS TLSConfig `yaml:"tls" toml:"tls"`
+
+	// Экспорт метрик в OpenTelemetry Collector наряду с отправкой на API
+	OTLP OTLPConfig `yaml:"otlp" toml:"otlp"`
+
+	// Отправка метрик в InfluxDB (line protocol) и Graphite (plaintext)
+	Influx   InfluxConfig   `yaml:"influx" toml:"influx"`
+	Graphite GraphiteConfig `yaml:"graphite" toml:"graphite"`
+
+	// Шаблон text/template для вывода collect: путь к файлу или имя
+	// встроенного шаблона (motd, status); пустой - вывод в формате -format
+	Template string `yaml:"template" toml:"template"`
+
+	// Поля, не входящие в ContentHash (см. DefaultHashExcludeFields)
+	HashExcludeFields []string `yaml:"hash_exclude_fields" toml:"hash_exclude_fields"`
+
+	// Сжатие тела запроса: "none", "gzip" или "zstd"
+	Compression          string `yaml:"compression" toml:"compression"`
+	CompressionThreshold int    `yaml:"compression_threshold" toml:"compression_threshold"` // Тела меньше этого размера (байт) не сжимаются
+
+	// Параллельный сбор секций
+	Workers          int           `yaml:"workers" toml:"workers"`                     // Число одновременно работающих сборщиков
+	CollectorTimeout time.Duration `yaml:"collector_timeout" toml:"collector_timeout"` // Срок сбора одной секции, 0 - без ограничения
+	ReportTimeout    time.Duration `yaml:"report_timeout" toml:"report_timeout"`       // Срок сбора всего отчета, 0 - без ограничения
+
+	// Путь к сокету Docker Engine API
+	DockerSocket string `yaml:"docker_socket" toml:"docker_socket"`
+
+	// Источники данных секции SECURITY STATUS
+	Security SecurityConfig `yaml:"security" toml:"security"`
+
+	// Режим агента (Reporter.Run)
+	Interval time.Duration `yaml:"interval" toml:"interval"` // Период отправки отчетов
+	Jitter   time.Duration `yaml:"jitter" toml:"jitter"`     // Максимальная случайная добавка к периоду
+
+	// Адрес HTTP-сервера метрик Prometheus (/metrics) в режиме агента,
+	// например ":9273"; пустой - сервер не запускается
+	MetricsListen string `yaml:"metrics_listen" toml:"metrics_listen"`
+
+	// Очередь неотправленных отчетов; пустой SpoolDir отключает очередь
+	SpoolDir      string        `yaml:"spool_dir" toml:"spool_dir"`
+	SpoolMaxBytes int64         `yaml:"spool_max_bytes" toml:"spool_max_bytes"`
+	SpoolMaxAge   time.Duration `yaml:"spool_max_age" toml:"spool_max_age"`
+
+	// Повторы отправки и автомат размыкания
+	Retry RetryConfig `yaml:"retry" toml:"retry"`
+
+	// Дельта-отправка: только секции, изменившиеся с последней успешной отправки
+	Delta              bool          `yaml:"delta" toml:"delta"`
+	StateFile          string        `yaml:"state_file" toml:"state_file"`                     // Файл с хешами последнего доставленного отчета
+	FullResyncEvery    int           `yaml:"full_resync_every" toml:"full_resync_every"`       // Полный отчет каждые N отправок
+	FullResyncInterval time.Duration `yaml:"full_resync_interval" toml:"full_resync_interval"` // и не реже этого интервала
+	// Поля, не учитываемые при сравнении секций (пути как в HashExcludeFields).
+	// По умолчанию пусто: любое изменение данных секции, включая загрузку
+	// CPU, попадает в дельту.
+	DeltaExcludeFields []string `yaml:"delta_exclude_fields" toml:"delta_exclude_fields"`
 }
 
+// APIVersion версия формата отчета (см. ReportSchema)
+const APIVersion = "1.0"
+
 // Структуры для JSON отчета
 type SystemReport struct {
 	APIVersion string    `json:"api_version"`
@@ -21,22 +89,26 @@ type SystemReport struct {
 }
 
 type Report struct {
-	HostID       string            `json:"host_id"`
-	ReportNumber int               `json:"report_number"`
-	Timestamp    time.Time         `json:"timestamp"`
+	HostID       string             `json:"host_id"`
+	ReportNumber int                `json:"report_number"`
+	Timestamp    time.Time          `json:"timestamp"`
 	Sections     map[string]Section `json:"sections"`
 }
 
 type Section struct {
+	Name  string      `json:"name,omitempty"`
 	Title string      `json:"title"`
 	Data  interface{} `json:"data"`
+	// Status и Error заполняются, если данные секции получить не удалось
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
 }
 
 // Структуры для данных разделов
 type HostInfo struct {
-	Hostname string    `json:"hostname"`
-	OS       string    `json:"os"`
-	Kernel   string    `json:"kernel"`
+	Hostname string     `json:"hostname"`
+	OS       string     `json:"os"`
+	Kernel   string     `json:"kernel"`
 	Uptime   UptimeInfo `json:"uptime"`
 }
 
@@ -46,11 +118,11 @@ type UptimeInfo struct {
 }
 
 type CPUInfo struct {
-	Model        string   `json:"model"`
-	Cores        int32    `json:"cores"`
-	Threads      int      `json:"threads"`
-	UsagePercent float64  `json:"usage_percent"`
-	LoadAverage  LoadAvg  `json:"load_average"`
+	Model        string  `json:"model"`
+	Cores        int32   `json:"cores"`
+	Threads      int     `json:"threads"`
+	UsagePercent float64 `json:"usage_percent"`
+	LoadAverage  LoadAvg `json:"load_average"`
 }
 
 type LoadAvg struct {
@@ -65,13 +137,13 @@ type MemoryInfo struct {
 }
 
 type RAMInfo struct {
-	TotalGB      float64 `json:"total_gb"`
-	AvailableGB  float64 `json:"available_gb"`
-	UsedGB       float64 `json:"used_gb"`
-	UsedPercent  float64 `json:"used_percent"`
-	FreeGB       float64 `json:"free_gb"`
-	CachedGB     float64 `json:"cached_gb"`
-	BuffersMB    float64 `json:"buffers_mb"`
+	TotalGB     float64 `json:"total_gb"`
+	AvailableGB float64 `json:"available_gb"`
+	UsedGB      float64 `json:"used_gb"`
+	UsedPercent float64 `json:"used_percent"`
+	FreeGB      float64 `json:"free_gb"`
+	CachedGB    float64 `json:"cached_gb"`
+	BuffersMB   float64 `json:"buffers_mb"`
 }
 
 type SwapInfo struct {
@@ -122,14 +194,33 @@ type DockerContainer struct {
 }
 
 type SecurityStatus struct {
-	Fail2ban            string `json:"fail2ban"`
-	UfwStatus           string `json:"ufw_status"`
-	LastUpdates         string `json:"last_updates"`
-	SSHFailedAttempts   int    `json:"ssh_failed_attempts"`
-}
-
-// Структура для отправки отчета на API
+	Fail2ban          string         `json:"fail2ban"`
+	Fail2banJails     []Fail2banJail `json:"fail2ban_jails,omitempty"`
+	UfwStatus         string         `json:"ufw_status"`
+	UfwRules          int            `json:"ufw_rules"`
+	LastUpdates       string         `json:"last_updates"`
+	LastUpgrade       *time.Time     `json:"last_upgrade,omitempty"`
+	PackagesUpgraded  int            `json:"packages_upgraded"`
+	DaysSinceUpgrade  *int           `json:"days_since_upgrade,omitempty"`
+	UpdatesLog        string         `json:"updates_log,omitempty"`
+	SSHFailedAttempts int            `json:"ssh_failed_attempts"`
+	SSHWindowHours    float64        `json:"ssh_window_hours"`
+	SSHLog            string         `json:"ssh_log,omitempty"`
+}
+
+type Fail2banJail struct {
+	Name   string `json:"name"`
+	Banned int    `json:"banned"`
+}
+
+// Структура для отправки отчета на API.
+// При дельта-отправке Report содержит только изменившиеся секции,
+// а BaseHash ссылается на предыдущий доставленный отчет.
 type APIReportRequest struct {
-	Agent  string                 `json:"agent"`
-	Report map[string]interface{} `json:"report"`
+	Agent           string                 `json:"agent"`
+	Report          map[string]interface{} `json:"report"`
+	Delta           bool                   `json:"delta,omitempty"`
+	BaseHash        string                 `json:"base_hash,omitempty"`
+	ReportHash      string                 `json:"report_hash,omitempty"`
+	RemovedSections []string               `json:"removed_sections,omitempty"`
 }
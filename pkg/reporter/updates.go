@@ -0,0 +1,268 @@
+package reporter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// upgradeRunGap максимальный промежуток между событиями одного обновления
+// в журналах без явных границ запуска (dpkg, dnf, yum)
+const upgradeRunGap = 5 * time.Minute
+
+// upgradeRun последний запуск обновления пакетов по данным одного журнала
+type upgradeRun struct {
+	Time     time.Time
+	Packages int
+	Source   string
+}
+
+// lastUpgrade находит последнее обновление пакетов по всем журналам.
+// Источники перечислены по убыванию точности: если два источника описывают
+// один и тот же запуск, используется более точный.
+func (s *securityCollector) lastUpgrade(ctx context.Context) (*upgradeRun, error) {
+	now := s.now()
+	parsers := []struct {
+		path  string
+		parse func(r io.Reader, now time.Time) (*upgradeRun, error)
+	}{
+		{s.config.AptHistoryLog, parseAptHistory},
+		{s.config.DpkgLog, parseDpkgLog},
+		{s.config.DnfLog, parseDnfLog},
+		{s.config.YumLog, parseYumLog},
+	}
+
+	var best *upgradeRun
+	for _, p := range parsers {
+		for _, file := range rotatedLogFiles(p.path) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			run, err := parseLogFile(file, now, p.parse)
+			if err != nil {
+				// Нечитаемый журнал не отменяет остальные источники
+				logf("Warning: %v\n", err)
+				continue
+			}
+			if run == nil {
+				continue
+			}
+			if best == nil || run.Time.After(best.Time.Add(upgradeRunGap)) {
+				run.Source = file
+				best = run
+			}
+		}
+	}
+	return best, nil
+}
+
+// rotatedLogFiles возвращает журнал и его ротированные копии (.1, .2.gz, ...)
+func rotatedLogFiles(path string) []string {
+	if path == "" {
+		return nil
+	}
+	files := []string{path}
+	rotated, _ := filepath.Glob(path + ".*")
+	sort.Strings(rotated)
+	return append(files, rotated...)
+}
+
+// parseLogFile открывает журнал, распаковывая .gz, и разбирает его.
+// Отсутствующий журнал - не ошибка: run и err равны nil.
+func parseLogFile(path string, now time.Time, parse func(r io.Reader, now time.Time) (*upgradeRun, error)) (*upgradeRun, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	run, err := parse(r, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return run, nil
+}
+
+// maxLogLine предел длины строки журнала
+const maxLogLine = 1024 * 1024
+
+// readLogLines вызывает fn для каждой строки r без перевода строки.
+// Строки длиннее limit пропускаются: в отличие от bufio.Scanner с
+// ErrTooLong, одна длинная строка не прерывает чтение журнала.
+func readLogLines(r io.Reader, limit int, fn func(line string)) error {
+	br := bufio.NewReader(r)
+	var line []byte
+	skipping := false
+	for {
+		chunk, isPrefix, err := br.ReadLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if !skipping {
+			if len(line)+len(chunk) > limit {
+				skipping = true
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if isPrefix {
+			continue
+		}
+		if !skipping {
+			fn(string(line))
+		}
+		line, skipping = line[:0], false
+	}
+}
+
+// parseAptHistory разбирает /var/log/apt/history.log:
+//
+//	Start-Date: 2024-01-15  10:22:33
+//	Upgrade: libc6:amd64 (2.36-9, 2.36-9+deb12u4), tzdata:amd64 (...)
+//	End-Date: 2024-01-15  10:23:01
+func parseAptHistory(r io.Reader, now time.Time) (*upgradeRun, error) {
+	var last *upgradeRun
+	var start, end time.Time
+	packages := 0
+
+	flush := func() {
+		if packages > 0 {
+			t := end
+			if t.IsZero() {
+				t = start
+			}
+			if !t.IsZero() && (last == nil || t.After(last.Time)) {
+				last = &upgradeRun{Time: t, Packages: packages}
+			}
+		}
+		start, end, packages = time.Time{}, time.Time{}, 0
+	}
+
+	// Строка Upgrade большого обновления бывает длиннее мегабайта
+	err := readLogLines(r, 4*maxLogLine, func(line string) {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			if strings.TrimSpace(line) == "" {
+				flush()
+			}
+			return
+		}
+		switch key {
+		case "Start-Date":
+			flush()
+			start = parseAptDate(value, now)
+		case "End-Date":
+			end = parseAptDate(value, now)
+		case "Upgrade":
+			packages += strings.Count(value, " (")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	flush()
+	return last, nil
+}
+
+func parseAptDate(value string, now time.Time) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", strings.Join(strings.Fields(value), " "), now.Location())
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseDpkgLog разбирает строки "2024-01-15 10:22:40 upgrade pkg:amd64 1.0 1.1"
+func parseDpkgLog(r io.Reader, now time.Time) (*upgradeRun, error) {
+	var events []time.Time
+	err := readLogLines(r, maxLogLine, func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != "upgrade" {
+			return
+		}
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", fields[0]+" "+fields[1], now.Location())
+		if err == nil {
+			events = append(events, t)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lastRunOf(events), nil
+}
+
+// parseDnfLog разбирает dnf.rpm.log: "2024-01-15T10:22:33+0000 SUBDEBUG Upgrade: pkg-1.1.x86_64"
+func parseDnfLog(r io.Reader, _ time.Time) (*upgradeRun, error) {
+	var events []time.Time
+	err := readLogLines(r, maxLogLine, func(line string) {
+		if !strings.Contains(line, " Upgrade: ") {
+			return
+		}
+		field, _, _ := strings.Cut(line, " ")
+		for _, layout := range []string{"2006-01-02T15:04:05-0700", time.RFC3339} {
+			if t, err := time.Parse(layout, field); err == nil {
+				events = append(events, t)
+				break
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lastRunOf(events), nil
+}
+
+// parseYumLog разбирает yum.log: "Jan 15 10:22:33 Updated: pkg-1.1.x86_64"
+func parseYumLog(r io.Reader, now time.Time) (*upgradeRun, error) {
+	var events []time.Time
+	err := readLogLines(r, maxLogLine, func(line string) {
+		if !strings.Contains(line, " Updated: ") {
+			return
+		}
+		if t, ok := parseSyslogTime(line, now); ok {
+			events = append(events, t)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lastRunOf(events), nil
+}
+
+// lastRunOf группирует события обновления пакетов в последний запуск
+func lastRunOf(events []time.Time) *upgradeRun {
+	if len(events) == 0 {
+		return nil
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Before(events[j]) })
+
+	last := len(events) - 1
+	first := last
+	for first > 0 && events[first].Sub(events[first-1]) <= upgradeRunGap {
+		first--
+	}
+	return &upgradeRun{Time: events[last], Packages: last - first + 1}
+}
@@ -0,0 +1,205 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// fixtureNow момент сбора для журналов из testdata/security
+var fixtureNow = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+const securityTestdata = "testdata/security"
+
+func newFixtureCollector(config SecurityConfig) *securityCollector {
+	s := newSecurityCollector(config)
+	s.now = func() time.Time { return fixtureNow }
+	return s
+}
+
+func TestParseUpgradeLogs(t *testing.T) {
+	logs := filepath.Join(securityTestdata, "log")
+	tests := []struct {
+		name     string
+		file     string
+		parse    func(r io.Reader, now time.Time) (*upgradeRun, error)
+		time     time.Time
+		packages int
+	}{
+		// Запуск apt install без Upgrade не считается обновлением
+		{"apt history", "apt/history.log", parseAptHistory, time.Date(2024, 3, 8, 6, 25, 40, 0, time.UTC), 2},
+		{"apt rotated", "apt/history.log.1", parseAptHistory, time.Date(2024, 2, 20, 6, 25, 10, 0, time.UTC), 1},
+		{"apt gzip", "apt/history.log.2.gz", parseAptHistory, time.Date(2024, 1, 15, 10, 23, 1, 0, time.UTC), 3},
+		{"dpkg", "dpkg.log", parseDpkgLog, time.Date(2024, 3, 8, 6, 25, 20, 0, time.UTC), 2},
+		{"dnf", "dnf.rpm.log", parseDnfLog, time.Date(2024, 3, 5, 3, 12, 0, 0, time.UTC), 3},
+		// 02:30 отстоит от 02:03 больше чем на upgradeRunGap
+		{"yum", "yum.log", parseYumLog, time.Date(2024, 3, 2, 2, 30, 0, 0, time.UTC), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := parseLogFile(filepath.Join(logs, tt.file), fixtureNow, tt.parse)
+			if err != nil {
+				t.Fatalf("parseLogFile: %v", err)
+			}
+			if run == nil {
+				t.Fatal("no upgrade run found")
+			}
+			if !run.Time.Equal(tt.time) || run.Packages != tt.packages {
+				t.Errorf("run = %v, %d packages; want %v, %d", run.Time, run.Packages, tt.time, tt.packages)
+			}
+		})
+	}
+}
+
+func TestParseAptHistoryEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		want     time.Time
+		packages int
+	}{
+		{
+			name:     "missing end date",
+			log:      "Start-Date: 2024-03-01  10:00:00\nUpgrade: a:amd64 (1, 2)\n",
+			want:     time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			packages: 1,
+		},
+		{
+			name: "runs without blank lines",
+			log: "Start-Date: 2024-03-01  10:00:00\nUpgrade: a:amd64 (1, 2)\nEnd-Date: 2024-03-01  10:00:05\n" +
+				"Start-Date: 2024-03-02  10:00:00\nUpgrade: a:amd64 (2, 3), b:amd64 (1, 2)\nEnd-Date: 2024-03-02  10:00:07\n",
+			want:     time.Date(2024, 3, 2, 10, 0, 7, 0, time.UTC),
+			packages: 2,
+		},
+		{
+			name: "newest run wins regardless of order",
+			log: "Start-Date: 2024-03-05  10:00:00\nUpgrade: a:amd64 (1, 2)\nEnd-Date: 2024-03-05  10:00:05\n\n" +
+				"Start-Date: 2024-03-02  10:00:00\nUpgrade: a:amd64 (2, 3), b:amd64 (1, 2)\nEnd-Date: 2024-03-02  10:00:07\n",
+			want:     time.Date(2024, 3, 5, 10, 0, 5, 0, time.UTC),
+			packages: 1,
+		},
+		{
+			name:     "huge upgrade line",
+			log:      "Start-Date: 2024-03-01  10:00:00\nUpgrade: " + strings.Repeat("pkg:amd64 (1, 2), ", 100000) + "\nEnd-Date: 2024-03-01  10:30:00\n",
+			want:     time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+			packages: 100000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := parseAptHistory(strings.NewReader(tt.log), fixtureNow)
+			if err != nil {
+				t.Fatalf("parseAptHistory: %v", err)
+			}
+			if run == nil {
+				t.Fatal("no upgrade run found")
+			}
+			if !run.Time.Equal(tt.want) || run.Packages != tt.packages {
+				t.Errorf("run = %v, %d packages; want %v, %d", run.Time, run.Packages, tt.want, tt.packages)
+			}
+		})
+	}
+
+	if run, _ := parseAptHistory(strings.NewReader("Start-Date: 2024-03-01  10:00:00\nInstall: a:amd64 (1)\n"), fixtureNow); run != nil {
+		t.Errorf("install-only run counted as upgrade: %+v", run)
+	}
+}
+
+func TestRotatedLogFiles(t *testing.T) {
+	path := filepath.Join(securityTestdata, "log", "apt", "history.log")
+	want := []string{path, path + ".1", path + ".2.gz"}
+	if got := rotatedLogFiles(path); !reflect.DeepEqual(got, want) {
+		t.Errorf("rotatedLogFiles = %v, want %v", got, want)
+	}
+	if got := rotatedLogFiles(""); got != nil {
+		t.Errorf("rotatedLogFiles(\"\") = %v, want nil", got)
+	}
+}
+
+func TestLastUpgradeSources(t *testing.T) {
+	logs := filepath.Join(securityTestdata, "log")
+	missing := filepath.Join(t.TempDir(), "missing")
+	tests := []struct {
+		name   string
+		config SecurityConfig
+		source string
+		time   time.Time
+	}{
+		{
+			// dpkg описывает тот же запуск, что и apt, и не заменяет его
+			name:   "apt preferred over dpkg",
+			config: SecurityConfig{AptHistoryLog: filepath.Join(logs, "apt", "history.log"), DpkgLog: filepath.Join(logs, "dpkg.log"), DnfLog: missing, YumLog: missing},
+			source: filepath.Join(logs, "apt", "history.log"),
+			time:   time.Date(2024, 3, 8, 6, 25, 40, 0, time.UTC),
+		},
+		{
+			name:   "dnf only",
+			config: SecurityConfig{AptHistoryLog: missing, DpkgLog: missing, DnfLog: filepath.Join(logs, "dnf.rpm.log"), YumLog: missing},
+			source: filepath.Join(logs, "dnf.rpm.log"),
+			time:   time.Date(2024, 3, 5, 3, 12, 0, 0, time.UTC),
+		},
+		{
+			name:   "newer yum wins over older apt",
+			config: SecurityConfig{AptHistoryLog: filepath.Join(logs, "apt", "history.log.2.gz"), DpkgLog: missing, DnfLog: missing, YumLog: filepath.Join(logs, "yum.log")},
+			source: filepath.Join(logs, "yum.log"),
+			time:   time.Date(2024, 3, 2, 2, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := newFixtureCollector(tt.config).lastUpgrade(context.Background())
+			if err != nil {
+				t.Fatalf("lastUpgrade: %v", err)
+			}
+			if run == nil {
+				t.Fatal("no upgrade run found")
+			}
+			if run.Source != tt.source || !run.Time.Equal(tt.time) {
+				t.Errorf("run = %s from %s; want %s from %s", run.Time, run.Source, tt.time, tt.source)
+			}
+		})
+	}
+}
+
+func TestParseLogFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "history.log.2.gz")
+	if err := os.WriteFile(corrupt, []byte("\x1f\x8bnot gzip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if run, err := parseLogFile(filepath.Join(dir, "missing.log"), fixtureNow, parseDpkgLog); run != nil || err != nil {
+		t.Errorf("missing log = %v, %v; want nil, nil", run, err)
+	}
+	if _, err := parseLogFile(corrupt, fixtureNow, parseAptHistory); err == nil || !strings.Contains(err.Error(), corrupt) {
+		t.Errorf("corrupt gzip error = %v, want mentioning %s", err, corrupt)
+	}
+
+	parsers := map[string]func(r io.Reader, now time.Time) (*upgradeRun, error){
+		"apt": parseAptHistory, "dpkg": parseDpkgLog, "dnf": parseDnfLog, "yum": parseYumLog,
+	}
+	for name, parse := range parsers {
+		if _, err := parse(iotest.ErrReader(errors.New("read failed")), fixtureNow); err == nil {
+			t.Errorf("%s parser ignored read error", name)
+		}
+	}
+
+	// Поврежденный ротированный журнал apt не мешает найти обновление в dpkg
+	config := SecurityConfig{
+		AptHistoryLog: filepath.Join(dir, "history.log"),
+		DpkgLog:       filepath.Join(securityTestdata, "log", "dpkg.log"),
+		DnfLog:        filepath.Join(dir, "missing"),
+		YumLog:        filepath.Join(dir, "missing"),
+	}
+	run, err := newFixtureCollector(config).lastUpgrade(context.Background())
+	if err != nil || run == nil || run.Source != config.DpkgLog {
+		t.Errorf("lastUpgrade = %+v, %v; want run from %s", run, err, config.DpkgLog)
+	}
+}